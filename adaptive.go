@@ -0,0 +1,293 @@
+package pixl
+
+import (
+	"image"
+	"math"
+)
+
+// integral is a summed-area table of image values. Sum of any rectangle can
+// be read in O(1), so cost of local statistics does not depend on window size
+type integral struct {
+	w, h  int
+	sum   []float64
+	sqsum []float64
+}
+
+// newIntegral builds summed-area table of w*h values stored row by row.
+// Table of squared values is built only when squares is true
+func newIntegral(values []float64, w, h int, squares bool) *integral {
+	table := &integral{w: w, h: h, sum: make([]float64, (w+1)*(h+1))}
+	if squares {
+		table.sqsum = make([]float64, (w+1)*(h+1))
+	}
+
+	for y := 0; y < h; y++ {
+		rowSum, rowSqsum := 0.0, 0.0
+		for x := 0; x < w; x++ {
+			v := values[y*w+x]
+			rowSum += v
+			i := (y+1)*(w+1) + x + 1
+			table.sum[i] = table.sum[i-w-1] + rowSum
+			if squares {
+				rowSqsum += v * v
+				table.sqsum[i] = table.sqsum[i-w-1] + rowSqsum
+			}
+		}
+	}
+	return table
+}
+
+// window returns sum, sum of squares and amount of values in a square window
+// with given radius centered at (x, y), clipped to the image
+func (table *integral) window(x, y, radius int) (sum, sqsum float64, n int) {
+	x0, y0 := maxInt(x-radius, 0), maxInt(y-radius, 0)
+	x1, y1 := minInt(x+radius+1, table.w), minInt(y+radius+1, table.h)
+
+	stride := table.w + 1
+	a, b := y0*stride+x0, y0*stride+x1
+	c, d := y1*stride+x0, y1*stride+x1
+
+	sum = table.sum[d] - table.sum[b] - table.sum[c] + table.sum[a]
+	if table.sqsum != nil {
+		sqsum = table.sqsum[d] - table.sqsum[b] - table.sqsum[c] + table.sqsum[a]
+	}
+	return sum, sqsum, (x1 - x0) * (y1 - y0)
+}
+
+// rect returns sum of values in rectangle r
+func (table *integral) rect(r image.Rectangle) float64 {
+	stride := table.w + 1
+	a, b := r.Min.Y*stride+r.Min.X, r.Min.Y*stride+r.Max.X
+	c, d := r.Max.Y*stride+r.Min.X, r.Max.Y*stride+r.Max.X
+	return table.sum[d] - table.sum[b] - table.sum[c] + table.sum[a]
+}
+
+// boxMean returns mean of every square window with given radius
+func boxMean(values []float64, w, h, radius int) []float64 {
+	table := newIntegral(values, w, h, false)
+	means := make([]float64, len(values))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum, _, n := table.window(x, y, radius)
+			means[y*w+x] = sum / float64(n)
+		}
+	}
+	return means
+}
+
+// gaussianMean approximates gaussian weighted mean with three successive box
+// means, so it stays independent of sigma as well
+func gaussianMean(values []float64, w, h int, sigma float64) []float64 {
+	for _, size := range gaussianBoxes(sigma, 3) {
+		values = boxMean(values, w, h, size/2)
+	}
+	return values
+}
+
+// gaussianBoxes returns sizes of n box filters which applied one after
+// another approximate gaussian filter with given sigma
+func gaussianBoxes(sigma float64, n int) []int {
+	wIdeal := math.Sqrt(12*sigma*sigma/float64(n) + 1)
+	wl := int(math.Floor(wIdeal))
+	if wl%2 == 0 {
+		wl--
+	}
+	wu := wl + 2
+
+	mIdeal := (12*sigma*sigma - float64(n*wl*wl+4*n*wl+3*n)) / float64(-4*wl-4)
+	m := int(math.Round(mIdeal))
+
+	sizes := make([]int, n)
+	for i := range sizes {
+		if i < m {
+			sizes[i] = wl
+		} else {
+			sizes[i] = wu
+		}
+	}
+	return sizes
+}
+
+// grayValues returns pixels of 16-bit gray image row by row, scaled to 0-255
+// range without rounding
+func grayValues(img *image.Gray16) (values []float64, w, h int) {
+	bounds := img.Bounds()
+	w, h = bounds.Dx(), bounds.Dy()
+	values = make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			values[y*w+x] = float64(img.Gray16At(bounds.Min.X+x, bounds.Min.Y+y).Y) / 0x101
+		}
+	}
+	return values, w, h
+}
+
+// localThreshold turns every pixel greater than its own level into white
+func localThreshold(img *image.Gray16, values, levels []float64, invertColors bool) *image.Gray {
+	bounds := img.Bounds()
+	w := bounds.Dx()
+	out := image.NewGray(bounds)
+	for i, v := range values {
+		result := uint8(0x00)
+		if v > levels[i] {
+			result = 0xFF
+		}
+		if invertColors {
+			result = 255 - result
+		}
+		out.Pix[(i/w)*out.Stride+i%w] = result
+	}
+	return out
+}
+
+func (config Threshold) blockSize() int {
+	size := config.BlockSize
+	if size <= 1 {
+		size = 11
+	}
+	if size%2 == 0 {
+		size++
+	}
+	return size
+}
+
+// convertLocal thresholds every pixel with level computed from its
+// BlockSize x BlockSize neighbourhood
+func (config Threshold) convertLocal(img *image.Gray16) *image.Gray {
+	values, w, h := grayValues(img)
+	size := config.blockSize()
+
+	var levels []float64
+	switch config.Algorithm {
+	case ThresholdAlgorithms.AdaptiveGaussian:
+		// same sigma as used for a gaussian kernel of given size
+		sigma := 0.3*(float64(size-1)*0.5-1) + 0.8
+		levels = gaussianMean(values, w, h, sigma)
+		for i := range levels {
+			levels[i] -= config.C
+		}
+	case ThresholdAlgorithms.Niblack, ThresholdAlgorithms.Sauvola, ThresholdAlgorithms.Wolf:
+		levels = config.statisticLevels(values, w, h, size/2)
+	case ThresholdAlgorithms.Bernsen:
+		levels = config.bernsenLevels(values, w, h, size/2)
+	default: //ThresholdAlgorithms.AdaptiveMean
+		levels = boxMean(values, w, h, size/2)
+		for i := range levels {
+			levels[i] -= config.C
+		}
+	}
+	return localThreshold(img, values, levels, config.InvertColors)
+}
+
+// statisticLevels computes levels of Niblack, Sauvola and Wolf-Jolion methods
+// from local mean and standard deviation
+func (config Threshold) statisticLevels(values []float64, w, h, radius int) []float64 {
+	table := newIntegral(values, w, h, true)
+	means := make([]float64, len(values))
+	deviations := make([]float64, len(values))
+
+	maxDeviation, minValue := 0.0, math.Inf(1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum, sqsum, n := table.window(x, y, radius)
+			mean := sum / float64(n)
+			deviation := math.Sqrt(math.Max(sqsum/float64(n)-mean*mean, 0))
+
+			i := y*w + x
+			means[i], deviations[i] = mean, deviation
+			maxDeviation = math.Max(maxDeviation, deviation)
+			minValue = math.Min(minValue, values[i])
+		}
+	}
+
+	k, r := 0.5, config.R
+	if config.Algorithm == ThresholdAlgorithms.Niblack {
+		k = -0.2
+	}
+	if config.K != nil {
+		k = *config.K
+	}
+	if r == 0 {
+		r = 128
+	}
+
+	levels := make([]float64, len(values))
+	for i, m := range means {
+		s := deviations[i]
+		switch config.Algorithm {
+		case ThresholdAlgorithms.Niblack:
+			levels[i] = m + k*s
+		case ThresholdAlgorithms.Sauvola:
+			levels[i] = m * (1 + k*(s/r-1))
+		default: //ThresholdAlgorithms.Wolf
+			contrast := 0.0
+			if maxDeviation > 0 {
+				contrast = s / maxDeviation
+			}
+			levels[i] = m - k*(1-contrast)*(m-minValue)
+		}
+	}
+	return levels
+}
+
+// bernsenLevels computes level as midrange of local minimum and maximum.
+// Pixels of low contrast neighbourhood are compared with mid-gray instead
+func (config Threshold) bernsenLevels(values []float64, w, h, radius int) []float64 {
+	minimums := slidingExtreme(values, w, h, radius, false)
+	maximums := slidingExtreme(values, w, h, radius, true)
+
+	limit := float64(config.ContrastLimit)
+	if limit == 0 {
+		limit = 15
+	}
+
+	levels := make([]float64, len(values))
+	for i := range levels {
+		if maximums[i]-minimums[i] < limit {
+			levels[i] = 127.5
+		} else {
+			levels[i] = (maximums[i] + minimums[i]) / 2
+		}
+	}
+	return levels
+}
+
+// slidingExtreme returns minimum (or maximum) of every square window with
+// given radius. Windows are separable, so rows and columns are filtered one
+// after another with monotonic queues in O(1) per pixel
+func slidingExtreme(values []float64, w, h, radius int, maximum bool) []float64 {
+	better := func(a, b float64) bool {
+		if maximum {
+			return a >= b
+		}
+		return a <= b
+	}
+
+	filter := func(src, dst []float64, offset, stride, length int) {
+		queue := make([]int, 0, length)
+		next := 0
+		for i := 0; i < length; i++ {
+			for ; next < length && next <= i+radius; next++ {
+				v := src[offset+next*stride]
+				for len(queue) > 0 && better(v, src[offset+queue[len(queue)-1]*stride]) {
+					queue = queue[:len(queue)-1]
+				}
+				queue = append(queue, next)
+			}
+			for queue[0] < i-radius {
+				queue = queue[1:]
+			}
+			dst[offset+i*stride] = src[offset+queue[0]*stride]
+		}
+	}
+
+	rows := make([]float64, len(values))
+	for y := 0; y < h; y++ {
+		filter(values, rows, y*w, 1, w)
+	}
+	result := make([]float64, len(values))
+	for x := 0; x < w; x++ {
+		filter(rows, result, x, w, h)
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_integralWindow(t *testing.T) {
+	w, h := 7, 5
+	values := make([]float64, w*h)
+	for i := range values {
+		values[i] = float64(i % 11)
+	}
+	table := newIntegral(values, w, h, true)
+
+	for _, center := range []image.Point{{0, 0}, {3, 2}, {6, 4}, {1, 3}} {
+		sum, sqsum, n := table.window(center.X, center.Y, 2)
+
+		expectedSum, expectedSqsum, expectedN := 0.0, 0.0, 0
+		for y := center.Y - 2; y <= center.Y+2; y++ {
+			for x := center.X - 2; x <= center.X+2; x++ {
+				if x < 0 || y < 0 || x >= w || y >= h {
+					continue
+				}
+				v := values[y*w+x]
+				expectedSum += v
+				expectedSqsum += v * v
+				expectedN++
+			}
+		}
+
+		if sum != expectedSum || sqsum != expectedSqsum || n != expectedN {
+			t.Errorf("Invalid window at %v, got: (%v, %v, %d), want: (%v, %v, %d).",
+				center, sum, sqsum, n, expectedSum, expectedSqsum, expectedN)
+		}
+	}
+}
+
+func Test_gaussianBoxes(t *testing.T) {
+	for _, sigma := range []float64{0.8, 2, 5.5} {
+		variance := 0.0
+		for _, size := range gaussianBoxes(sigma, 3) {
+			variance += float64(size*size-1) / 12
+		}
+		if math.Abs(math.Sqrt(variance)-sigma) > 0.5 {
+			t.Errorf("Invalid boxes for sigma %v, got sigma: %v.", sigma, math.Sqrt(variance))
+		}
+	}
+}
+
+// generateUnevenDocument returns image with vertical text lines drawn on
+// background getting brighter from left to right, and amount of text pixels
+func generateUnevenDocument() (image.Image, int) {
+	size := 40
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	textPixels := 0
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			background := uint8(60 + 4*x)
+			if x%8 == 4 {
+				img.Set(x, y, color.Gray{Y: background - 40})
+				textPixels++
+			} else {
+				img.Set(x, y, color.Gray{Y: background})
+			}
+		}
+	}
+	return img, textPixels
+}
+
+func TestThresholdAdaptive(t *testing.T) {
+	input, textPixels := generateUnevenDocument()
+
+	for _, algorithm := range []thresholdAlgoName{ThresholdAlgorithms.AdaptiveMean, ThresholdAlgorithms.AdaptiveGaussian} {
+		out := Threshold{Algorithm: algorithm, BlockSize: 11, C: 15}.Convert(input)
+
+		blackPixels := 0
+		for i, v := range out.Pix {
+			x := i % out.Stride
+			if v == 0x00 {
+				blackPixels++
+				if x%8 != 4 {
+					t.Errorf("Background pixel turned black for %s at x: %d.", algorithm, x)
+					break
+				}
+			}
+		}
+
+		if blackPixels != textPixels {
+			t.Errorf("Invalid number of black pixels for %s, got: %d, want: %d.", algorithm, blackPixels, textPixels)
+		}
+	}
+}
+
+func Test_slidingExtreme(t *testing.T) {
+	w, h, radius := 6, 4, 1
+	values := make([]float64, w*h)
+	for i := range values {
+		values[i] = float64((i * 7) % 13)
+	}
+
+	minimums := slidingExtreme(values, w, h, radius, false)
+	maximums := slidingExtreme(values, w, h, radius, true)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			expectedMin, expectedMax := math.Inf(1), math.Inf(-1)
+			for j := maxInt(y-radius, 0); j < minInt(y+radius+1, h); j++ {
+				for i := maxInt(x-radius, 0); i < minInt(x+radius+1, w); i++ {
+					expectedMin = math.Min(expectedMin, values[j*w+i])
+					expectedMax = math.Max(expectedMax, values[j*w+i])
+				}
+			}
+			if minimums[y*w+x] != expectedMin || maximums[y*w+x] != expectedMax {
+				t.Errorf("Invalid extremes at (%d, %d), got: (%v, %v), want: (%v, %v).",
+					x, y, minimums[y*w+x], maximums[y*w+x], expectedMin, expectedMax)
+			}
+		}
+	}
+}
+
+func TestThresholdDocumentBinarization(t *testing.T) {
+	input, textPixels := generateUnevenDocument()
+	k := 0.1
+
+	tests := []struct {
+		config      Threshold
+		exactlyText bool
+	}{
+		{Threshold{Algorithm: ThresholdAlgorithms.Sauvola, BlockSize: 11, K: &k}, true},
+		{Threshold{Algorithm: ThresholdAlgorithms.Wolf, BlockSize: 11}, true},
+		{Threshold{Algorithm: ThresholdAlgorithms.Bernsen, BlockSize: 11}, true},
+		// Niblack is known to mark noise in background as well
+		{Threshold{Algorithm: ThresholdAlgorithms.Niblack, BlockSize: 11}, false},
+	}
+
+	for _, test := range tests {
+		out := test.config.Convert(input)
+
+		blackPixels, blackText := 0, 0
+		for i, v := range out.Pix {
+			if v == 0x00 {
+				blackPixels++
+				if i%out.Stride%8 == 4 {
+					blackText++
+				}
+			}
+		}
+
+		if blackText != textPixels {
+			t.Errorf("Invalid number of black text pixels for %s, got: %d, want: %d.", test.config.Algorithm, blackText, textPixels)
+		}
+		if test.exactlyText && blackPixels != textPixels {
+			t.Errorf("Invalid number of black pixels for %s, got: %d, want: %d.", test.config.Algorithm, blackPixels, textPixels)
+		}
+	}
+}
+
+func TestThresholdNiblackLocalMean(t *testing.T) {
+	input, _ := generateUnevenDocument()
+
+	// K 0 compares pixels with plain local mean, same as AdaptiveMean
+	k := 0.0
+	niblack := Threshold{Algorithm: ThresholdAlgorithms.Niblack, BlockSize: 11, K: &k}.Convert(input)
+	mean := Threshold{Algorithm: ThresholdAlgorithms.AdaptiveMean, BlockSize: 11}.Convert(input)
+	for i := range mean.Pix {
+		if niblack.Pix[i] != mean.Pix[i] {
+			t.Fatalf("Niblack with K 0 should threshold with local mean, pixel %d differs.", i)
+		}
+	}
+
+	defaults := Threshold{Algorithm: ThresholdAlgorithms.Niblack, BlockSize: 11}.Convert(input)
+	differ := 0
+	for i := range mean.Pix {
+		if defaults.Pix[i] != mean.Pix[i] {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Errorf("Niblack without K should use default K -0.2.")
+	}
+}
+
+func BenchmarkThresholdSauvola(b *testing.B) {
+	b.StopTimer()
+	input := generateImage()
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		Threshold{
+			Algorithm: ThresholdAlgorithms.Sauvola,
+		}.Convert(input)
+	}
+}
+
+func BenchmarkThresholdAdaptiveMean(b *testing.B) {
+	b.StopTimer()
+	input := generateImage()
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		Threshold{
+			Algorithm: ThresholdAlgorithms.AdaptiveMean,
+		}.Convert(input)
+	}
+}
@@ -0,0 +1,126 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+//Brightness is a config struct
+//Configuration contains:
+//  Amount - value added to every channel, from -1 (black) to 1 (white)
+type Brightness struct {
+	Amount float64
+}
+
+//Convert takes an image as an input and returns image with adjusted brightness
+func (config Brightness) Convert(input image.Image) image.Image {
+	return adjustColors(input, func(r, g, b float64) (float64, float64, float64) {
+		return r + config.Amount, g + config.Amount, b + config.Amount
+	})
+}
+
+//Contrast is a config struct
+//Configuration contains:
+//  Amount - -1 gives flat gray, 0 keeps the image and 1 doubles distance
+//      of channels from the middle gray
+type Contrast struct {
+	Amount float64
+}
+
+//Convert takes an image as an input and returns image with adjusted contrast
+func (config Contrast) Convert(input image.Image) image.Image {
+	factor := math.Max(0, 1+config.Amount)
+	return adjustColors(input, func(r, g, b float64) (float64, float64, float64) {
+		return (r-0.5)*factor + 0.5, (g-0.5)*factor + 0.5, (b-0.5)*factor + 0.5
+	})
+}
+
+//Exposure is a config struct
+//Configuration contains:
+//  Stops - linear light is multiplied by 2^Stops like exposure of camera,
+//      e.g. 1 doubles the light and -1 halves it
+type Exposure struct {
+	Stops float64
+}
+
+//Convert takes an image as an input and returns image with adjusted exposure
+func (config Exposure) Convert(input image.Image) image.Image {
+	factor := math.Pow(2, config.Stops)
+	expose := func(v float64) float64 {
+		return linearToSRGB(math.Min(1, srgbToLinear(v)*factor))
+	}
+	return adjustColors(input, func(r, g, b float64) (float64, float64, float64) {
+		return expose(r), expose(g), expose(b)
+	})
+}
+
+//Saturation is a config struct
+//Configuration contains:
+//  Saturation - -1 gives grayscale, 0 keeps the image and 1 doubles
+//      distance of channels from luma
+//  Vibrance - works like Saturation, but affects less saturated colors
+//      more, so skin tones and already vivid colors are kept
+type Saturation struct {
+	Saturation float64
+	Vibrance   float64
+}
+
+//Convert takes an image as an input and returns image with adjusted saturation
+func (config Saturation) Convert(input image.Image) image.Image {
+	return adjustColors(input, func(r, g, b float64) (float64, float64, float64) {
+		_, s, _ := rgbToHSV(r, g, b)
+		factor := math.Max(0, (1+config.Saturation)*(1+config.Vibrance*(1-s)))
+
+		y := luma(r, g, b)
+		return y + (r-y)*factor, y + (g-y)*factor, y + (b-y)*factor
+	})
+}
+
+//HueRotate is a config struct
+//Configuration contains:
+//  Degrees - angle of rotation of hue in CIE L*a*b*, which keeps
+//      lightness and chroma of colors
+type HueRotate struct {
+	Degrees float64
+}
+
+//Convert takes an image as an input and returns image with rotated hue
+func (config HueRotate) Convert(input image.Image) image.Image {
+	sin, cos := math.Sincos(config.Degrees * math.Pi / 180)
+	return adjustColors(input, func(r, g, b float64) (float64, float64, float64) {
+		l, a, bb := rgbToLab(r, g, b)
+		return labToRGB(l, a*cos-bb*sin, a*sin+bb*cos)
+	})
+}
+
+// adjustColors applies f to every pixel of the image keeping its alpha.
+// Returns *image.RGBA64 for 16-bit images and *image.RGBA for other images
+func adjustColors(input image.Image, f func(r, g, b float64) (float64, float64, float64)) image.Image {
+	var output draw.Image
+	if is16Bit(input) {
+		output = image.NewRGBA64(input.Bounds())
+	} else {
+		output = image.NewRGBA(input.Bounds())
+	}
+	traverseImage(input, output, colorAdjustment{adjust: f})
+	return output
+}
+
+// colorAdjustment passes non-premultiplied channels in range 0-1 to adjust
+// and clamps its results
+type colorAdjustment struct {
+	adjust func(r, g, b float64) (float64, float64, float64)
+}
+
+func (config colorAdjustment) transform(input color.Color) color.Color {
+	c := color.NRGBA64Model.Convert(input).(color.NRGBA64)
+	r, g, b := config.adjust(float64(c.R)/0xFFFF, float64(c.G)/0xFFFF, float64(c.B)/0xFFFF)
+
+	clamp := func(v float64) uint16 {
+		return uint16(math.Max(0, math.Min(0xFFFF, math.Round(v*0xFFFF))))
+	}
+
+	return color.NRGBA64{R: clamp(r), G: clamp(g), B: clamp(b), A: c.A}
+}
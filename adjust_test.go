@@ -0,0 +1,82 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func singlePixel(c color.Color) *image.NRGBA {
+	input := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	input.Set(0, 0, c)
+	return input
+}
+
+func TestColorAdjustments(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   image.Image
+		expected color.NRGBA
+	}{
+		{"brightness", Brightness{Amount: 0.2}.Convert(singlePixel(color.NRGBA{R: 100, G: 200, B: 240, A: 0xFF})),
+			color.NRGBA{R: 151, G: 251, B: 255, A: 0xFF}},
+		{"contrast", Contrast{Amount: 1}.Convert(singlePixel(color.NRGBA{R: 100, G: 128, B: 200, A: 0xFF})),
+			color.NRGBA{R: 72, G: 129, B: 255, A: 0xFF}},
+		{"flat contrast", Contrast{Amount: -1}.Convert(singlePixel(color.NRGBA{R: 0, G: 100, B: 255, A: 0xFF})),
+			color.NRGBA{R: 128, G: 128, B: 128, A: 0xFF}},
+		// half of the linear light of 255 is 188 in sRGB
+		{"exposure", Exposure{Stops: -1}.Convert(singlePixel(color.NRGBA{R: 255, G: 0, B: 255, A: 0xFF})),
+			color.NRGBA{R: 188, G: 0, B: 188, A: 0xFF}},
+		{"desaturation", Saturation{Saturation: -1}.Convert(singlePixel(color.NRGBA{R: 255, G: 0, B: 0, A: 0xFF})),
+			color.NRGBA{R: 76, G: 76, B: 76, A: 0xFF}},
+		{"hue rotation", HueRotate{Degrees: 360}.Convert(singlePixel(color.NRGBA{R: 30, G: 120, B: 200, A: 0xFF})),
+			color.NRGBA{R: 30, G: 120, B: 200, A: 0xFF}},
+	}
+
+	for _, test := range tests {
+		c := color.NRGBAModel.Convert(test.output.At(0, 0)).(color.NRGBA)
+		if c != test.expected {
+			t.Errorf("Invalid color after %s, got: %v, want: %v.", test.name, c, test.expected)
+		}
+	}
+}
+
+func TestVibrance(t *testing.T) {
+	// vibrance changes dull colors more than vivid ones
+	dull := color.NRGBA{R: 140, G: 120, B: 120, A: 0xFF}
+	vivid := color.NRGBA{R: 240, G: 20, B: 20, A: 0xFF}
+	config := Saturation{Vibrance: 0.5}
+
+	dullOut := color.NRGBAModel.Convert(config.Convert(singlePixel(dull)).At(0, 0)).(color.NRGBA)
+	vividOut := color.NRGBAModel.Convert(config.Convert(singlePixel(vivid)).At(0, 0)).(color.NRGBA)
+
+	dullGain := float64(dullOut.R-dullOut.G) / float64(dull.R-dull.G)
+	vividGain := float64(vividOut.R-vividOut.G) / float64(vivid.R-vivid.G)
+	if dullGain <= vividGain {
+		t.Errorf("Vibrance should saturate dull colors more, got gains: %v and %v.", dullGain, vividGain)
+	}
+}
+
+func TestHueRotateKeepsLightness(t *testing.T) {
+	input := color.NRGBA{R: 180, G: 90, B: 100, A: 0xFF}
+	out := color.NRGBAModel.Convert(HueRotate{Degrees: 120}.Convert(singlePixel(input)).At(0, 0)).(color.NRGBA)
+	l, _, _ := rgbToLab(float64(input.R)/255, float64(input.G)/255, float64(input.B)/255)
+	lOut, _, _ := rgbToLab(float64(out.R)/255, float64(out.G)/255, float64(out.B)/255)
+
+	if out == input || lOut-l > 1 || l-lOut > 1 {
+		t.Errorf("Invalid rotated color, got: %v with lightness %v, want lightness %v.", out, lOut, l)
+	}
+}
+
+func TestAdjust16Bit(t *testing.T) {
+	input := image.NewGray16(image.Rect(0, 0, 1, 1))
+	input.SetGray16(0, 0, color.Gray16{Y: 0x1001})
+
+	out, ok := Contrast{}.Convert(input).(*image.RGBA64)
+	if !ok {
+		t.Fatalf("16-bit image should be adjusted into *image.RGBA64.")
+	}
+	if v := out.RGBA64At(0, 0).R; v != 0x1001 {
+		t.Errorf("Invalid 16-bit value, got: %#x, want: %#x.", v, 0x1001)
+	}
+}
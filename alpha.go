@@ -0,0 +1,105 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+)
+
+type alphaPolicyName string
+
+type alphaPolicyList struct {
+	Ignore    alphaPolicyName
+	Preserve  alphaPolicyName
+	Composite alphaPolicyName
+	White     alphaPolicyName
+}
+
+// AlphaPolicies consists of a list of policies describing how transparent
+// pixels are handled by pixl.Gray and pixl.Threshold. e.g.
+// pixl.Gray{Alpha: pixl.AlphaPolicies.Composite, Background: "#fffff0"}
+//
+// Ignore (default) uses premultiplied colors as they are, so semi-transparent
+// pixels become darker and transparent pixels become black. ConvertAlpha
+// keeps alpha channel, so it converts un-premultiplied colors as Preserve.
+// Preserve converts un-premultiplied colors and keeps alpha channel in
+// ConvertAlpha output.
+// Composite blends pixels over Background color.
+// White blends pixels over white color.
+var AlphaPolicies = &alphaPolicyList{
+	Ignore:    "ignore",
+	Preserve:  "preserve",
+	Composite: "composite",
+	White:     "white",
+}
+
+// keepsAlpha reports whether alpha channel is left in output of ConvertAlpha
+func (policy alphaPolicyName) keepsAlpha() bool {
+	return policy != AlphaPolicies.Composite && policy != AlphaPolicies.White
+}
+
+func newAlphaTransformer(policy alphaPolicyName, background string, next transformer) transformer {
+	switch policy {
+	case AlphaPolicies.Preserve:
+		return alphaPreserve{next: next}
+	case AlphaPolicies.Composite:
+		c, err := parseHexColor(background)
+		if err != nil {
+			c = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+		}
+		return alphaComposite{background: c, next: next}
+	case AlphaPolicies.White:
+		return alphaComposite{background: color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, next: next}
+	}
+	return next
+}
+
+type alphaPreserve struct {
+	next transformer
+}
+
+func (config alphaPreserve) transform(input color.Color) color.Color {
+	c := color.NRGBA64Model.Convert(input).(color.NRGBA64)
+	c.A = 0xFFFF
+	return config.next.transform(c)
+}
+
+type alphaComposite struct {
+	background color.RGBA
+	next       transformer
+}
+
+func (config alphaComposite) transform(input color.Color) color.Color {
+	r, g, b, a := input.RGBA()
+	br, bg, bb, _ := config.background.RGBA()
+
+	// input is premultiplied, so only background has to be scaled
+	blend := func(c, bc uint32) uint16 {
+		return uint16(c + bc*(0xFFFF-a)/0xFFFF)
+	}
+
+	return config.next.transform(color.RGBA64{
+		R: blend(r, br),
+		G: blend(g, bg),
+		B: blend(b, bb),
+		A: 0xFFFF,
+	})
+}
+
+// withAlpha combines gray levels of img with alpha channel of alpha image
+func withAlpha(img image.Image, alpha image.Image) *image.NRGBA {
+	output := image.NewNRGBA(img.Bounds())
+	w, h := output.Bounds().Max.X, output.Bounds().Max.Y
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			v, _, _, _ := img.At(x, y).RGBA()
+			_, _, _, a := alpha.At(x, y).RGBA()
+			output.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(v >> 8),
+				G: uint8(v >> 8),
+				B: uint8(v >> 8),
+				A: uint8(a >> 8),
+			})
+		}
+	}
+	return output
+}
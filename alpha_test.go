@@ -0,0 +1,93 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestGrayAlphaPolicies(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.Set(0, 0, color.NRGBA{R: 200, G: 200, B: 200, A: 0x80})
+	input.Set(1, 0, color.NRGBA{R: 10, G: 10, B: 10, A: 0})
+
+	tests := []struct {
+		policy     alphaPolicyName
+		background string
+		expected   [2]uint8
+	}{
+		{AlphaPolicies.Ignore, "", [2]uint8{100, 0}},
+		{AlphaPolicies.Preserve, "", [2]uint8{200, 0}},
+		{AlphaPolicies.White, "", [2]uint8{228, 255}},
+		{AlphaPolicies.Composite, "#000000", [2]uint8{100, 0}},
+		{AlphaPolicies.Composite, "#646464", [2]uint8{150, 100}},
+	}
+
+	for _, test := range tests {
+		out := Gray{Algorithm: GrayAlgorithms.Average, Alpha: test.policy, Background: test.background}.Convert(input)
+
+		for x, expected := range test.expected {
+			if y := out.GrayAt(x, 0).Y; y != expected {
+				t.Errorf("Invalid output color for %s, got: %d, want: %d.", test.policy, y, expected)
+			}
+		}
+	}
+}
+
+func TestGrayConvertAlpha(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	input.Set(0, 0, color.NRGBA{R: 200, G: 200, B: 200, A: 0x80})
+
+	out := Gray{Algorithm: GrayAlgorithms.Average, Alpha: AlphaPolicies.Preserve}.ConvertAlpha(input)
+	if c, expected := out.NRGBAAt(0, 0), (color.NRGBA{R: 200, G: 200, B: 200, A: 0x80}); c != expected {
+		t.Errorf("Invalid output color, got: %v, want: %v.", c, expected)
+	}
+
+	out = Gray{Algorithm: GrayAlgorithms.Average, Alpha: AlphaPolicies.White}.ConvertAlpha(input)
+	if a := out.NRGBAAt(0, 0).A; a != 0xFF {
+		t.Errorf("Invalid output alpha, got: %d, want: %d.", a, 0xFF)
+	}
+
+	// default policy keeps alpha, so colors are not darkened by it twice
+	out = Gray{Algorithm: GrayAlgorithms.Average}.ConvertAlpha(input)
+	if c, expected := out.NRGBAAt(0, 0), (color.NRGBA{R: 200, G: 200, B: 200, A: 0x80}); c != expected {
+		t.Errorf("Invalid output color with default policy, got: %v, want: %v.", c, expected)
+	}
+	thresholded := Threshold{Algorithm: ThresholdAlgorithms.Static}.ConvertAlpha(input)
+	if c, expected := thresholded.NRGBAAt(0, 0), (color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80}); c != expected {
+		t.Errorf("Invalid thresholded color with default policy, got: %v, want: %v.", c, expected)
+	}
+}
+
+func TestThresholdAlphaWhite(t *testing.T) {
+	size := 10
+	input := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if j < 4 {
+				input.Set(i, j, color.NRGBA{R: 20, G: 20, B: 20, A: 0xFF})
+			} else {
+				input.Set(i, j, color.Transparent)
+			}
+		}
+	}
+
+	out := Threshold{Algorithm: ThresholdAlgorithms.Static, Alpha: AlphaPolicies.White}.Convert(input)
+	whitePixels := 0
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if out.GrayAt(i, j).Y == 0xFF {
+				whitePixels++
+			}
+		}
+	}
+
+	if expected := 60; whitePixels != expected {
+		t.Errorf("Invalid number of white pixels in image, got: %d, want: %d.", whitePixels, expected)
+	}
+
+	alpha := Threshold{Algorithm: ThresholdAlgorithms.Static, Alpha: AlphaPolicies.Preserve}.ConvertAlpha(input)
+	if a := alpha.NRGBAAt(0, size-1).A; a != 0 {
+		t.Errorf("Invalid output alpha, got: %d, want: %d.", a, 0)
+	}
+}
@@ -0,0 +1,246 @@
+package pixl
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+)
+
+//GaussianBlur is a config struct
+//Configuration contains:
+//  Sigma - standard deviation of gaussian in pixels (default 1)
+//  Border - reading of pixels out of the image (default clamp,
+//      see pixl.BorderModes)
+type GaussianBlur struct {
+	Sigma  float64
+	Border borderModeName
+}
+
+//Convert takes an image as an input and returns blurred image. Gray images
+//are returned as *image.Gray or *image.Gray16, color images as *image.RGBA
+//or *image.RGBA64
+func (config GaussianBlur) Convert(input image.Image) image.Image {
+	sigma := config.Sigma
+	if sigma <= 0 {
+		sigma = 1
+	}
+
+	// 3 sigma covers 99.7% of the gaussian
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	p := newPlanes(input, filterBorder(config.Border), "")
+	return p.convolve1D(kernel, true).convolve1D(kernel, false).image(input)
+}
+
+//BoxBlur is a config struct
+//Configuration contains:
+//  Radius - every pixel becomes mean of (2*Radius+1)x(2*Radius+1) window
+//      (default 1)
+//  Border - reading of pixels out of the image (default clamp,
+//      see pixl.BorderModes)
+type BoxBlur struct {
+	Radius int
+	Border borderModeName
+}
+
+//Convert takes an image as an input and returns blurred image. Gray images
+//are returned as *image.Gray or *image.Gray16, color images as *image.RGBA
+//or *image.RGBA64. Cost per pixel doesn't depend on the radius
+func (config BoxBlur) Convert(input image.Image) image.Image {
+	radius := config.Radius
+	if radius <= 0 {
+		radius = 1
+	}
+	p := newPlanes(input, filterBorder(config.Border), "")
+	return p.boxMean(radius, true).boxMean(radius, false).image(input)
+}
+
+// boxMean returns mean of every 2*radius+1 pixels long window along rows
+// (horizontal) or columns, keeping running sums of windows
+func (p *planes) boxMean(radius int, horizontal bool) *planes {
+	result := p.empty()
+	size := 2*radius + 1
+	length, lines := p.w, p.h
+	if !horizontal {
+		length, lines = p.h, p.w
+	}
+	at := func(line, i int) []float64 {
+		if horizontal {
+			return p.pixel(i, line)
+		}
+		return p.pixel(line, i)
+	}
+
+	parallelBands(lines, func(from, to int) {
+		sum := make([]float64, p.channels)
+		for line := from; line < to; line++ {
+			for c := range sum {
+				sum[c] = 0
+			}
+			for i := -radius; i <= radius; i++ {
+				for c, v := range at(line, i) {
+					sum[c] += v
+				}
+			}
+			for i := 0; i < length; i++ {
+				index := line*p.w + i
+				if !horizontal {
+					index = i*p.w + line
+				}
+				for c := range sum {
+					result.data[p.channels*index+c] = sum[c] / float64(size)
+				}
+				added, removed := at(line, i+radius+1), at(line, i-radius)
+				for c := range sum {
+					sum[c] += added[c] - removed[c]
+				}
+			}
+		}
+	})
+	return result
+}
+
+//Median is a config struct
+//Configuration contains:
+//  Radius - every channel becomes median of (2*Radius+1)x(2*Radius+1)
+//      window (default 1)
+//  Border - reading of pixels out of the image (default clamp,
+//      see pixl.BorderModes)
+type Median struct {
+	Radius int
+	Border borderModeName
+}
+
+//Convert takes an image as an input and returns image filtered by median,
+//which removes speckles and keeps edges. Gray images are returned as
+//*image.Gray or *image.Gray16, color images as *image.RGBA or *image.RGBA64.
+//Cost per pixel of 8-bit images doesn't depend on the radius
+func (config Median) Convert(input image.Image) image.Image {
+	radius := config.Radius
+	if radius <= 0 {
+		radius = 1
+	}
+	p := newPlanes(input, filterBorder(config.Border), "")
+	if is16Bit(input) {
+		return p.medianSorted(radius).image(input)
+	}
+	return p.medianHistogram(radius).image(input)
+}
+
+// medianHistogram finds medians of 8-bit levels in histograms of windows.
+// Histogram of every column of the window is kept and moved down with
+// the rows, histogram of the window is updated by one column added and one
+// removed, so each pixel costs O(1) updates (Perreault and Hébert)
+func (p *planes) medianHistogram(radius int) *planes {
+	if p.w == 0 || p.h == 0 {
+		return p
+	}
+	result := p.empty()
+	size := 2*radius + 1
+	pw := p.w + 2*radius
+
+	// channels of pixels padded by radius, quantized to 8 bits
+	padded := make([][]uint8, p.channels)
+	for c := range padded {
+		padded[c] = make([]uint8, pw*(p.h+2*radius))
+	}
+	for y := 0; y < p.h+2*radius; y++ {
+		for x := 0; x < pw; x++ {
+			for c, v := range p.pixel(x-radius, y-radius) {
+				padded[c][y*pw+x] = uint8(math.Round(math.Max(0, math.Min(1, v)) * 0xFF))
+			}
+		}
+	}
+
+	// every band keeps its own column histograms, reused by all channels
+	bands := medianBands(pw, p.h, runtime.NumCPU())
+	parallelBands(bands, func(first, last int) {
+		columns := make([]Histogram, pw)
+		window := &Histogram{}
+		for band := first; band < last; band++ {
+			from, to := band*p.h/bands, (band+1)*p.h/bands
+			for c, levels := range padded {
+				for x := range columns {
+					columns[x] = Histogram{}
+					for y := from; y < from+size; y++ {
+						columns[x][levels[y*pw+x]]++
+					}
+				}
+
+				for y := from; y < to; y++ {
+					if y > from {
+						for x := range columns {
+							columns[x][levels[(y-1)*pw+x]]--
+							columns[x][levels[(y+size-1)*pw+x]]++
+						}
+					}
+
+					*window = Histogram{}
+					for x := 0; x < size; x++ {
+						for t, amount := range columns[x] {
+							window[t] += amount
+						}
+					}
+					for x := 0; x < p.w; x++ {
+						if x > 0 {
+							added, removed := &columns[x+size-1], &columns[x-1]
+							for t := range window {
+								window[t] += added[t] - removed[t]
+							}
+						}
+						result.data[p.channels*(y*p.w+x)+c] = float64(window.Median()) / 0xFF
+					}
+				}
+			}
+		}
+	})
+	return result
+}
+
+// medianMemory limits memory of column histograms of all bands in bytes
+const medianMemory = 64 << 20
+
+// medianBands returns number of bands of medianHistogram, one band per CPU
+// unless column histograms of pw wide image would exceed medianMemory
+func medianBands(pw, h, cpus int) int {
+	perBand := pw * len(Histogram{}) * strconv.IntSize / 8
+	return maxInt(1, minInt(minInt(cpus, h), medianMemory/perBand))
+}
+
+// medianSorted finds medians by sorting every window, used for 16-bit
+// images which levels don't fit into small histograms
+func (p *planes) medianSorted(radius int) *planes {
+	result := p.empty()
+	size := 2*radius + 1
+	parallelBands(p.h, func(from, to int) {
+		values := make([]float64, size*size)
+		for y := from; y < to; y++ {
+			for x := 0; x < p.w; x++ {
+				for c := 0; c < p.channels; c++ {
+					i := 0
+					for dy := -radius; dy <= radius; dy++ {
+						for dx := -radius; dx <= radius; dx++ {
+							values[i] = p.pixel(x+dx, y+dy)[c]
+							i++
+						}
+					}
+					sort.Float64s(values)
+					result.data[p.channels*(y*p.w+x)+c] = values[len(values)/2]
+				}
+			}
+		}
+	})
+	return result
+}
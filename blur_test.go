@@ -0,0 +1,134 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// randomImage is w x h gray image of random levels
+func randomImage(w, h int) *image.Gray {
+	input := image.NewGray(image.Rect(0, 0, w, h))
+	random := rand.New(rand.NewSource(1))
+	for i := range input.Pix {
+		input.Pix[i] = uint8(random.Intn(256))
+	}
+	return input
+}
+
+func TestGaussianBlur(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 15, 15))
+	input.SetGray(7, 7, color.Gray{Y: 0xFF})
+
+	narrow := GaussianBlur{Sigma: 1}.Convert(input).(*image.Gray)
+	wide := GaussianBlur{Sigma: 2}.Convert(input).(*image.Gray)
+	if narrow.GrayAt(7, 7).Y <= wide.GrayAt(7, 7).Y {
+		t.Errorf("Wider gaussian should spread the peak more, got: %d and %d.", narrow.GrayAt(7, 7).Y, wide.GrayAt(7, 7).Y)
+	}
+	if narrow.GrayAt(6, 7) != narrow.GrayAt(7, 8) {
+		t.Errorf("Gaussian blur should be symmetric, got: %d and %d.", narrow.GrayAt(6, 7).Y, narrow.GrayAt(7, 8).Y)
+	}
+
+	constant := image.NewGray(image.Rect(0, 0, 5, 5))
+	traverseImage(constant, constant, paintAll{color: color.Gray{Y: 90}})
+	if v := (GaussianBlur{Sigma: 3}).Convert(constant).(*image.Gray).GrayAt(0, 0).Y; v != 90 {
+		t.Errorf("Edges of constant image should keep their level, got: %d, want: %d.", v, 90)
+	}
+}
+
+func TestBoxBlur(t *testing.T) {
+	input := randomImage(13, 9)
+
+	for _, radius := range []int{1, 3} {
+		size := 2*radius + 1
+		kernel := make([][]float64, size)
+		for i := range kernel {
+			kernel[i] = make([]float64, size)
+			for j := range kernel[i] {
+				kernel[i][j] = 1
+			}
+		}
+
+		box := BoxBlur{Radius: radius}.Convert(input).(*image.Gray)
+		convolved := Convolve{Kernel: kernel, Normalize: true, Border: BorderModes.Clamp}.Convert(input).(*image.Gray)
+		for i := range box.Pix {
+			if box.Pix[i] != convolved.Pix[i] {
+				t.Errorf("Box blur with radius %d should equal mean kernel, got: %d, want: %d.", radius, box.Pix[i], convolved.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestMedianSpeckle(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 5, 5))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 100}})
+	input.SetGray(2, 2, color.Gray{Y: 0xFF})
+	input.SetGray(0, 0, color.Gray{Y: 0x00})
+
+	out := Median{}.Convert(input).(*image.Gray)
+	for i, v := range out.Pix {
+		if v != 100 {
+			t.Errorf("Speckles should be removed, got: %d at %d, want: %d.", v, i, 100)
+		}
+	}
+}
+
+func TestMedianHistogram(t *testing.T) {
+	// histogram based median gives the same levels as sorting windows
+	input := randomImage(17, 11)
+	for _, border := range []borderModeName{BorderModes.Clamp, BorderModes.Reflect, BorderModes.Constant} {
+		p := newPlanes(input, border, "#808080")
+		histogram := p.medianHistogram(2).image(input).(*image.Gray)
+		sorted := p.medianSorted(2).image(input).(*image.Gray)
+		for i := range histogram.Pix {
+			if histogram.Pix[i] != sorted.Pix[i] {
+				t.Errorf("Invalid median with %s border, got: %d, want: %d.", border, histogram.Pix[i], sorted.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestMedianBands(t *testing.T) {
+	tests := []struct {
+		pw, h, cpus int
+		expected    int
+	}{
+		{100, 100, 16, 16},
+		{100, 3, 16, 3},
+		// 6000 px wide image would need 16 x 12 MB of column histograms
+		{6002, 4000, 16, 5},
+		{100000, 4000, 16, 1},
+	}
+	for _, test := range tests {
+		if bands := medianBands(test.pw, test.h, test.cpus); bands != test.expected {
+			t.Errorf("Invalid number of bands for width %d, got: %d, want: %d.", test.pw, bands, test.expected)
+		}
+	}
+}
+
+func BenchmarkMedian(b *testing.B) {
+	img := generateImage()
+	for i := 0; i < b.N; i++ {
+		Median{Radius: 5}.Convert(img)
+	}
+}
+
+func TestBlurEmpty(t *testing.T) {
+	for _, bounds := range []image.Rectangle{image.Rect(0, 0, 0, 0), image.Rect(0, 0, 3, 0), image.Rect(0, 0, 0, 3)} {
+		for _, input := range []image.Image{image.NewGray(bounds), image.NewRGBA(bounds), image.NewGray16(bounds)} {
+			outputs := []image.Image{
+				GaussianBlur{}.Convert(input),
+				BoxBlur{}.Convert(input),
+				Median{}.Convert(input),
+			}
+			for _, out := range outputs {
+				if out.Bounds() != bounds {
+					t.Errorf("Invalid bounds of blurred empty image, got: %v, want: %v.", out.Bounds(), bounds)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package pixl
+
+import (
+	"math"
+)
+
+// D65 reference white used by CIE conversions
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// luma returns Y of YCbCr (ITU-R BT.601) in the same range as channels
+func luma(r, g, b float64) float64 {
+	return 0.299*r + 0.587*g + 0.114*b
+}
+
+// srgbToLinear converts sRGB channel in range 0-1 into linear light
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts linear light in range 0-1 into sRGB channel
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// rgbToLab converts sRGB channels in range 0-1 into CIE L*a*b*
+func rgbToLab(r, g, b float64) (l, a, bb float64) {
+	r, g, b = srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / whiteX
+	y := (0.2126*r + 0.7152*g + 0.0722*b) / whiteY
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / whiteZ
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389.0 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27.0*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// labToRGB converts CIE L*a*b* into sRGB channels clamped to range 0-1
+func labToRGB(l, a, bb float64) (r, g, b float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - bb/200
+
+	finv := func(t float64) float64 {
+		if t > 6.0/29.0 {
+			return t * t * t
+		}
+		return (116*t - 16) * 27.0 / 24389.0
+	}
+	x, y, z := finv(fx)*whiteX, finv(fy)*whiteY, finv(fz)*whiteZ
+
+	clamp := func(v float64) float64 {
+		return math.Max(0, math.Min(1, v))
+	}
+	r = clamp(linearToSRGB(clamp(3.2406*x - 1.5372*y - 0.4986*z)))
+	g = clamp(linearToSRGB(clamp(-0.9689*x + 1.8758*y + 0.0415*z)))
+	b = clamp(linearToSRGB(clamp(0.0557*x - 0.2040*y + 1.0570*z)))
+	return r, g, b
+}
@@ -0,0 +1,135 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+type colorSpaceName string
+
+type colorSpaceList struct {
+	RGB colorSpaceName
+	HSV colorSpaceName
+}
+
+// ColorSpaces consists of a list of color spaces that can be used as
+// space type in pixl.ColorThreshold struct. e.g.
+// pixl.ColorThreshold{Space: pixl.ColorSpaces.HSV}
+var ColorSpaces = &colorSpaceList{
+	RGB: "rgb",
+	HSV: "hsv",
+}
+
+// Range is an inclusive range of channel values, e.g. {Min: 0, Max: 0}
+// selects only pixels with the channel equal to 0. Nil range means the whole
+// range of the channel
+type Range struct {
+	Min, Max float64
+}
+
+func (r *Range) contains(v float64) bool {
+	if r == nil {
+		return true
+	}
+	return v >= r.Min && v <= r.Max
+}
+
+// containsHue works like contains, but ranges with Min greater than Max
+// wrap around 360 degrees, e.g. {Min: 340, Max: 20} selects reds
+func (r *Range) containsHue(h float64) bool {
+	if r == nil || r.Min <= r.Max {
+		return r.contains(h)
+	}
+	return h >= r.Min || h <= r.Max
+}
+
+//ColorThreshold is a config struct
+//Configuration contains:
+//  Space - color space of ranges (default RGB)
+//  Red, Green, Blue - ranges 0-255 of channels used in RGB space
+//  Hue - range of hue in degrees 0-360 used in HSV space
+//  Saturation, Value - ranges 0-1 used in HSV space
+//  Ranges left nil don't limit their channels
+//  InvertColors - if true then pixels outside of ranges are selected
+type ColorThreshold struct {
+	Space        colorSpaceName
+	Red          *Range
+	Green        *Range
+	Blue         *Range
+	Hue          *Range
+	Saturation   *Range
+	Value        *Range
+	InvertColors bool
+}
+
+//Convert takes an image as an input and returns binary mask, white pixels
+//of the mask have all channels within ranges
+func (config ColorThreshold) Convert(input image.Image) *image.Gray {
+	output := image.NewGray(input.Bounds())
+	traverseImage(input, output, colorRange{config: config})
+	return output
+}
+
+//ConvertAlpha takes an image as an input and returns binary mask, opaque
+//pixels of the mask have all channels within ranges
+func (config ColorThreshold) ConvertAlpha(input image.Image) *image.Alpha {
+	mask := config.Convert(input)
+	return &image.Alpha{Pix: mask.Pix, Stride: mask.Stride, Rect: mask.Rect}
+}
+
+type colorRange struct {
+	config ColorThreshold
+}
+
+func (t colorRange) transform(input color.Color) color.Color {
+	c := color.NRGBA64Model.Convert(input).(color.NRGBA64)
+	r, g, b := float64(c.R)/0x101, float64(c.G)/0x101, float64(c.B)/0x101
+
+	var inside bool
+	if t.config.Space == ColorSpaces.HSV {
+		h, s, v := rgbToHSV(r/255, g/255, b/255)
+		inside = t.config.Hue.containsHue(h) &&
+			t.config.Saturation.contains(s) &&
+			t.config.Value.contains(v)
+	} else {
+		inside = t.config.Red.contains(r) &&
+			t.config.Green.contains(g) &&
+			t.config.Blue.contains(b)
+	}
+
+	if inside != t.config.InvertColors {
+		return color.Gray{Y: 0xFF}
+	}
+	return color.Gray{Y: 0x00}
+}
+
+// rgbToHSV converts channels in range 0-1 into hue in degrees 0-360,
+// saturation and value in range 0-1
+func rgbToHSV(r, g, b float64) (h, s, v float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+
+	v = max
+	if max > 0 {
+		s = delta / max
+	}
+	if delta == 0 {
+		return 0, s, v
+	}
+
+	switch max {
+	case r:
+		h = math.Mod((g-b)/delta, 6)
+	case g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
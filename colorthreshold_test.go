@@ -0,0 +1,90 @@
+package pixl
+
+import (
+	"image"
+	"testing"
+)
+
+func generateColorBars() image.Image {
+	colors := []string{"#ff0000", "#f00a14", "#00ff00", "#0000ff", "#808080"}
+	img := image.NewNRGBA(image.Rect(0, 0, len(colors), 1))
+	for x, hex := range colors {
+		c, _ := parseHexColor(hex)
+		img.Set(x, 0, c)
+	}
+	return img
+}
+
+func TestColorThresholdRGB(t *testing.T) {
+	out := ColorThreshold{
+		Red:   &Range{Min: 200, Max: 255},
+		Green: &Range{Min: 0, Max: 50},
+	}.Convert(generateColorBars())
+
+	for x, expected := range []uint8{0xFF, 0xFF, 0x00, 0x00, 0x00} {
+		if y := out.GrayAt(x, 0).Y; y != expected {
+			t.Errorf("Invalid value of pixel %d, got: %d, want: %d.", x, y, expected)
+		}
+	}
+}
+
+func TestColorThresholdExactZero(t *testing.T) {
+	// range of a single value 0 selects only pixels without blue
+	out := ColorThreshold{Blue: &Range{Min: 0, Max: 0}}.Convert(generateColorBars())
+	for x, expected := range []uint8{0xFF, 0x00, 0xFF, 0x00, 0x00} {
+		if y := out.GrayAt(x, 0).Y; y != expected {
+			t.Errorf("Invalid value of pixel %d, got: %d, want: %d.", x, y, expected)
+		}
+	}
+
+	// nil ranges don't limit channels
+	all := ColorThreshold{}.Convert(generateColorBars())
+	for x := 0; x < 5; x++ {
+		if y := all.GrayAt(x, 0).Y; y != 0xFF {
+			t.Errorf("Invalid value of pixel %d, got: %d, want: %d.", x, y, 0xFF)
+		}
+	}
+}
+
+func TestColorThresholdHSV(t *testing.T) {
+	config := ColorThreshold{
+		Space:      ColorSpaces.HSV,
+		Hue:        &Range{Min: 340, Max: 20},
+		Saturation: &Range{Min: 0.5, Max: 1},
+	}
+
+	out := config.Convert(generateColorBars())
+	for x, expected := range []uint8{0xFF, 0xFF, 0x00, 0x00, 0x00} {
+		if y := out.GrayAt(x, 0).Y; y != expected {
+			t.Errorf("Invalid value of pixel %d, got: %d, want: %d.", x, y, expected)
+		}
+	}
+
+	config.InvertColors = true
+	alpha := config.ConvertAlpha(generateColorBars())
+	for x, expected := range []uint8{0x00, 0x00, 0xFF, 0xFF, 0xFF} {
+		if a := alpha.AlphaAt(x, 0).A; a != expected {
+			t.Errorf("Invalid alpha of pixel %d, got: %d, want: %d.", x, a, expected)
+		}
+	}
+}
+
+func Test_rgbToHSV(t *testing.T) {
+	tests := []struct {
+		r, g, b, h, s, v float64
+	}{
+		{1, 0, 0, 0, 1, 1},
+		{0, 1, 0, 120, 1, 1},
+		{0, 0, 1, 240, 1, 1},
+		{1, 0, 0.5, 330, 1, 1},
+		{0.5, 0.5, 0.5, 0, 0, 0.5},
+	}
+
+	for _, test := range tests {
+		h, s, v := rgbToHSV(test.r, test.g, test.b)
+		if h != test.h || s != test.s || v != test.v {
+			t.Errorf("Invalid HSV of (%v, %v, %v), got: (%v, %v, %v), want: (%v, %v, %v).",
+				test.r, test.g, test.b, h, s, v, test.h, test.s, test.v)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+//Convolve is a config struct
+//Configuration contains:
+//  Kernel - rows of weights, center of the kernel is at [len/2][len/2].
+//      Kernel is applied without flipping, as in most image editors.
+//      Opacity of color images is averaged with absolute weights, so edge
+//      detection kernels and kernels not summing to 1 keep opacity
+//  Normalize - if true then weights are divided by their sum
+//  Border - reading of pixels out of the image (default clamp,
+//      see pixl.BorderModes)
+//  Fill - color of constant border in hex format (e.g. #b690d9),
+//      transparent (black for gray images) when empty
+type Convolve struct {
+	Kernel    [][]float64
+	Normalize bool
+	Border    borderModeName
+	Fill      string
+}
+
+//Convert takes an image as an input and returns filtered image. Gray images
+//are returned as *image.Gray or *image.Gray16, color images as *image.RGBA
+//or *image.RGBA64. Separable kernels are applied in two 1D passes
+func (config Convolve) Convert(input image.Image) image.Image {
+	kernel := config.kernel()
+	p := newPlanes(input, filterBorder(config.Border), config.Fill)
+	if len(kernel) == 0 {
+		return p.image(input)
+	}
+
+	if column, row, ok := separate(kernel); ok {
+		p = p.convolve1D(row, true).convolve1D(column, false)
+	} else {
+		p = p.convolve2D(kernel)
+	}
+	return p.image(input)
+}
+
+// kernel returns copy of the kernel with rows of equal length, normalized
+// if required
+func (config Convolve) kernel() [][]float64 {
+	width := 0
+	for _, row := range config.Kernel {
+		width = maxInt(width, len(row))
+	}
+	if width == 0 {
+		return nil
+	}
+
+	sum := 0.0
+	kernel := make([][]float64, len(config.Kernel))
+	for i, row := range config.Kernel {
+		kernel[i] = make([]float64, width)
+		copy(kernel[i], row)
+		for _, v := range row {
+			sum += v
+		}
+	}
+	if config.Normalize && sum != 0 {
+		for _, row := range kernel {
+			for j := range row {
+				row[j] /= sum
+			}
+		}
+	}
+	return kernel
+}
+
+// separate returns column and row vectors which outer product is
+// the kernel, false when the kernel is not separable
+func separate(kernel [][]float64) (column, row []float64, ok bool) {
+	// the largest weight is the most precise pivot
+	pr, pc := 0, 0
+	for i := range kernel {
+		for j := range kernel[i] {
+			if math.Abs(kernel[i][j]) > math.Abs(kernel[pr][pc]) {
+				pr, pc = i, j
+			}
+		}
+	}
+	pivot := kernel[pr][pc]
+	if pivot == 0 {
+		return nil, nil, false
+	}
+
+	column = make([]float64, len(kernel))
+	row = make([]float64, len(kernel[0]))
+	for i := range kernel {
+		column[i] = kernel[i][pc]
+	}
+	for j := range kernel[pr] {
+		row[j] = kernel[pr][j] / pivot
+	}
+	for i := range kernel {
+		for j := range kernel[i] {
+			if math.Abs(column[i]*row[j]-kernel[i][j]) > 1e-9*math.Abs(pivot) {
+				return nil, nil, false
+			}
+		}
+	}
+	return column, row, true
+}
+
+// planes stores channels of an image as floats in range 0-1 pixel by pixel,
+// 1 channel of gray images and 4 premultiplied channels of other images.
+// Kernels are applied to color channels, alpha is filtered with absolute
+// weights normalized to sum 1, so it stays in range 0-1
+type planes struct {
+	data     []float64
+	w, h     int
+	channels int
+	border   borderModeName
+	fill     []float64
+}
+
+func newPlanes(input image.Image, border borderModeName, fill string) *planes {
+	bounds := input.Bounds()
+	p := &planes{w: bounds.Dx(), h: bounds.Dy(), border: border}
+
+	c, err := parseHexColor(fill)
+	var fillColor color.Color = c
+	if err != nil {
+		fillColor = color.RGBA{}
+	}
+
+	if isGray(input) {
+		p.channels = 1
+		p.data = make([]float64, p.w*p.h)
+		parallelBands(p.h, func(from, to int) {
+			for y := from; y < to; y++ {
+				for x := 0; x < p.w; x++ {
+					r, _, _, _ := input.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+					p.data[y*p.w+x] = float64(r) / 0xFFFF
+				}
+			}
+		})
+		p.fill = []float64{float64(color.Gray16Model.Convert(fillColor).(color.Gray16).Y) / 0xFFFF}
+		return p
+	}
+
+	p.channels = 4
+	p.data = premultiplied(input, false)
+	r, g, b, a := fillColor.RGBA()
+	p.fill = []float64{float64(r) / 0xFFFF, float64(g) / 0xFFFF, float64(b) / 0xFFFF, float64(a) / 0xFFFF}
+	return p
+}
+
+// isGray reports whether an image has only gray levels
+func isGray(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		return true
+	}
+	return false
+}
+
+// empty returns planes of the same size and properties without data
+func (p *planes) empty() *planes {
+	result := *p
+	result.data = make([]float64, len(p.data))
+	return &result
+}
+
+// pixel returns channels of pixel (x, y) read according to border mode
+func (p *planes) pixel(x, y int) []float64 {
+	x, insideX := p.border.index(x, p.w)
+	y, insideY := p.border.index(y, p.h)
+	if !insideX || !insideY {
+		return p.fill
+	}
+	i := p.channels * (y*p.w + x)
+	return p.data[i : i+p.channels]
+}
+
+// convolve1D applies kernel along rows (horizontal) or columns
+func (p *planes) convolve1D(kernel []float64, horizontal bool) *planes {
+	result := p.empty()
+	anchor := len(kernel) / 2
+	opacity := 1 / absSum(kernel)
+	parallelBands(p.h, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < p.w; x++ {
+				out := result.data[p.channels*(y*p.w+x):]
+				for k, weight := range kernel {
+					if weight == 0 {
+						continue
+					}
+					var in []float64
+					if horizontal {
+						in = p.pixel(x+k-anchor, y)
+					} else {
+						in = p.pixel(x, y+k-anchor)
+					}
+					p.add(out, in, weight, math.Abs(weight)*opacity)
+				}
+			}
+		}
+	})
+	return result
+}
+
+// convolve2D applies the whole kernel to every pixel
+func (p *planes) convolve2D(kernel [][]float64) *planes {
+	result := p.empty()
+	anchorY, anchorX := len(kernel)/2, len(kernel[0])/2
+	sum := 0.0
+	for _, row := range kernel {
+		sum += absSum(row)
+	}
+	opacity := 1 / sum
+	parallelBands(p.h, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < p.w; x++ {
+				out := result.data[p.channels*(y*p.w+x):]
+				for i, row := range kernel {
+					for j, weight := range row {
+						if weight == 0 {
+							continue
+						}
+						in := p.pixel(x+j-anchorX, y+i-anchorY)
+						p.add(out, in, weight, math.Abs(weight)*opacity)
+					}
+				}
+			}
+		}
+	})
+	return result
+}
+
+// add adds channels of pixel in multiplied by weight to out, alpha of color
+// planes is multiplied by alphaWeight
+func (p *planes) add(out, in []float64, weight, alphaWeight float64) {
+	if p.channels == 1 {
+		out[0] += in[0] * weight
+		return
+	}
+	out[0] += in[0] * weight
+	out[1] += in[1] * weight
+	out[2] += in[2] * weight
+	out[3] += in[3] * alphaWeight
+}
+
+// absSum returns sum of absolute values of weights
+func absSum(weights []float64) float64 {
+	sum := 0.0
+	for _, weight := range weights {
+		sum += math.Abs(weight)
+	}
+	return sum
+}
+
+// image returns planes as *image.Gray or *image.RGBA, 16-bit input gives
+// *image.Gray16 or *image.RGBA64
+func (p *planes) image(input image.Image) image.Image {
+	rect := image.Rect(0, 0, p.w, p.h)
+	if p.channels == 4 {
+		output := newColorImage(input, rect)
+		parallelBands(p.h, func(from, to int) {
+			for y := from; y < to; y++ {
+				for x := 0; x < p.w; x++ {
+					i := 4 * (y*p.w + x)
+					output.Set(x, y, fromPremultiplied(p.data[i:i+4], false))
+				}
+			}
+		})
+		return output
+	}
+
+	level := func(x, y int) float64 {
+		return math.Max(0, math.Min(1, p.data[y*p.w+x]))
+	}
+	if is16Bit(input) {
+		output := image.NewGray16(rect)
+		parallelBands(p.h, func(from, to int) {
+			for y := from; y < to; y++ {
+				for x := 0; x < p.w; x++ {
+					output.SetGray16(x, y, color.Gray16{Y: uint16(math.Round(level(x, y) * 0xFFFF))})
+				}
+			}
+		})
+		return output
+	}
+	output := image.NewGray(rect)
+	parallelBands(p.h, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < p.w; x++ {
+				output.SetGray(x, y, color.Gray{Y: uint8(math.Round(level(x, y) * 0xFF))})
+			}
+		}
+	})
+	return output
+}
+
+// filterBorder returns border mode of filters, which clamp by default so
+// smoothing doesn't darken edges or make them transparent
+func filterBorder(border borderModeName) borderModeName {
+	if border == "" {
+		return BorderModes.Clamp
+	}
+	return border
+}
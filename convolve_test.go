@@ -0,0 +1,149 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSeparate(t *testing.T) {
+	column, row, ok := separate([][]float64{{1, 2, 1}, {2, 4, 2}, {1, 2, 1}})
+	if !ok {
+		t.Fatalf("Gaussian kernel should be separable.")
+	}
+	for i := range column {
+		for j := range row {
+			if expected := []float64{1, 2, 1}[i] * []float64{1, 2, 1}[j]; column[i]*row[j] != expected {
+				t.Errorf("Invalid separated weight, got: %v, want: %v.", column[i]*row[j], expected)
+			}
+		}
+	}
+
+	if _, _, ok := separate([][]float64{{0, -1, 0}, {-1, 5, -1}, {0, -1, 0}}); ok {
+		t.Errorf("Sharpening kernel should not be separable.")
+	}
+}
+
+func TestConvolveGray(t *testing.T) {
+	input := numberedImage(4, 3)
+	blur := [][]float64{{1, 2, 1}, {2, 4, 2}, {1, 2, 1}}
+
+	// blur takes separable path, sharpening the generic one
+	blurred := Convolve{Kernel: blur, Normalize: true, Border: BorderModes.Clamp}.Convert(input).(*image.Gray)
+	sharpen := [][]float64{{0, -1, 0}, {-1, 5, -1}, {0, -1, 0}}
+	sharpened := Convolve{Kernel: sharpen, Border: BorderModes.Reflect}.Convert(blurred).(*image.Gray)
+
+	// inner pixels of the ramp are kept by both kernels
+	if v := blurred.GrayAt(1, 1).Y; v != 11 {
+		t.Errorf("Invalid blurred level, got: %d, want: %d.", v, 11)
+	}
+	if v := sharpened.GrayAt(1, 1).Y; v != 11 {
+		t.Errorf("Invalid sharpened level, got: %d, want: %d.", v, 11)
+	}
+}
+
+func TestConvolveBorders(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 3, 1))
+	for x, v := range []uint8{30, 60, 90} {
+		input.SetGray(x, 0, color.Gray{Y: v})
+	}
+	// picks the left neighbour
+	shift := [][]float64{{1, 0, 0}}
+
+	tests := []struct {
+		border   borderModeName
+		fill     string
+		expected uint8
+	}{
+		{BorderModes.Constant, "", 0},
+		{BorderModes.Constant, "#ffffff", 0xFF},
+		{BorderModes.Clamp, "", 30},
+		{BorderModes.Reflect, "", 30},
+		{BorderModes.Wrap, "", 90},
+	}
+	for _, test := range tests {
+		out := Convolve{Kernel: shift, Border: test.border, Fill: test.fill}.Convert(input).(*image.Gray)
+		if v := out.GrayAt(0, 0).Y; v != test.expected {
+			t.Errorf("Invalid level with %s border, got: %d, want: %d.", test.border, v, test.expected)
+		}
+		if v := out.GrayAt(2, 0).Y; v != 60 {
+			t.Errorf("Invalid shifted level, got: %d, want: %d.", v, 60)
+		}
+	}
+}
+
+func TestConvolveColor(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{G: 0xFF, A: 0x00})
+	out := Convolve{Kernel: [][]float64{{1, 1}}, Normalize: true, Border: BorderModes.Clamp}.Convert(input)
+
+	// transparent green doesn't bleed into red
+	c := color.NRGBAModel.Convert(out.At(1, 0)).(color.NRGBA)
+	if c.R != 0xFF || c.G != 0 || c.A != 0x80 {
+		t.Errorf("Invalid color, got: %v, want: {255 0 0 128}.", c)
+	}
+
+	wide := image.NewGray16(image.Rect(0, 0, 1, 1))
+	wide.SetGray16(0, 0, color.Gray16{Y: 0x1234})
+	if out, ok := (Convolve{Kernel: [][]float64{{1}}}).Convert(wide).(*image.Gray16); !ok || out.Gray16At(0, 0).Y != 0x1234 {
+		t.Errorf("16-bit gray image should be filtered into *image.Gray16 with 16-bit precision.")
+	}
+}
+
+func BenchmarkConvolve(b *testing.B) {
+	img := generateImage()
+	kernel := [][]float64{{0, -1, 0}, {-1, 5, -1}, {0, -1, 0}}
+	for i := 0; i < b.N; i++ {
+		Convolve{Kernel: kernel, Border: BorderModes.Clamp}.Convert(img)
+	}
+}
+
+func TestConvolveDefaultBorder(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	traverseImage(input, input, paintAll{color: color.NRGBA{R: 200, G: 100, B: 50, A: 0xFF}})
+	blur := [][]float64{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}}
+
+	// edges of constant image keep their color and opacity
+	c := color.NRGBAModel.Convert(Convolve{Kernel: blur, Normalize: true}.Convert(input).At(0, 0)).(color.NRGBA)
+	if c != (color.NRGBA{R: 200, G: 100, B: 50, A: 0xFF}) {
+		t.Errorf("Invalid color of the edge, got: %v, want: {200 100 50 255}.", c)
+	}
+}
+
+func TestConvolveColorEdges(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 4, 2))
+	rgba := image.NewRGBA(gray.Bounds())
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			gray.SetGray(x, y, color.Gray{Y: 200})
+		}
+		for x := 0; x < 4; x++ {
+			rgba.Set(x, y, gray.At(x, y))
+		}
+	}
+
+	// zero-sum kernel responds to the step in color channels and keeps
+	// opacity, same as for gray images
+	for _, kernel := range [][][]float64{{{-1, 0, 1}}, {{-1, 0, 1}, {-1, 0, 1}}, {{0, 0, 0}, {-1, 0, 1}, {0, 0, 0}}} {
+		expected := Convolve{Kernel: kernel}.Convert(gray).(*image.Gray)
+		out := Convolve{Kernel: kernel}.Convert(rgba)
+		for x := 0; x < 4; x++ {
+			want := color.RGBA{R: expected.GrayAt(x, 1).Y, G: expected.GrayAt(x, 1).Y, B: expected.GrayAt(x, 1).Y, A: 0xFF}
+			if c := color.RGBAModel.Convert(out.At(x, 1)).(color.RGBA); c != want {
+				t.Errorf("Invalid edge response of kernel %v at %d, got: %v, want: %v.", kernel, x, c, want)
+			}
+		}
+	}
+	if v := (Convolve{Kernel: [][]float64{{-1, 0, 1}}}).Convert(gray).(*image.Gray).GrayAt(1, 0).Y; v != 200 {
+		t.Errorf("Invalid edge response, got: %d, want: %d.", v, 200)
+	}
+
+	// kernel not summing to 1 scales colors but not opacity
+	input := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 50, G: 100, B: 200, A: 0x80})
+	c := color.NRGBAModel.Convert(Convolve{Kernel: [][]float64{{2}}}.Convert(input).At(0, 0)).(color.NRGBA)
+	if c.A != 0x80 || abs(int(c.R)-100) > 1 || abs(int(c.G)-200) > 1 || c.B != 0xFF {
+		t.Errorf("Invalid scaled color, got: %v, want: {100 200 255 128}.", c)
+	}
+}
@@ -0,0 +1,232 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+//Crop is a config struct
+//Configuration contains:
+//  Rect - cropped rectangle in coordinates of the input, ignored when empty
+//  Trim - if true then uniform borders of color of the top left pixel are
+//      removed
+//  Tolerance - maximal difference of channels (0-255) of trimmed pixels
+//      from the border color
+//  AspectRatio - if positive then the largest centered region with ratio
+//      of width to height equal to AspectRatio is kept
+//Steps are applied in order: Rect, Trim, AspectRatio
+type Crop struct {
+	Rect        image.Rectangle
+	Trim        bool
+	Tolerance   uint8
+	AspectRatio float64
+}
+
+//Convert takes an image as an input and returns cropped image of the same type
+func (config Crop) Convert(input image.Image) image.Image {
+	rect := input.Bounds()
+	if !config.Rect.Empty() {
+		rect = rect.Intersect(config.Rect)
+	}
+	if config.Trim {
+		rect = trimmedRect(input, rect, config.Tolerance)
+	}
+	if config.AspectRatio > 0 && !rect.Empty() {
+		rect = centeredRect(rect, config.AspectRatio)
+	}
+	return cropRect(input, rect)
+}
+
+// trimmedRect shrinks rect while its outer rows or columns consist of pixels
+// similar to its top left pixel. Uniform image is not trimmed at all
+func trimmedRect(input image.Image, rect image.Rectangle, tolerance uint8) image.Rectangle {
+	if rect.Empty() {
+		return rect
+	}
+	border := color.NRGBAModel.Convert(input.At(rect.Min.X, rect.Min.Y)).(color.NRGBA)
+	similar := func(x, y int) bool {
+		c := color.NRGBAModel.Convert(input.At(x, y)).(color.NRGBA)
+		for _, d := range []int{
+			int(c.R) - int(border.R), int(c.G) - int(border.G),
+			int(c.B) - int(border.B), int(c.A) - int(border.A),
+		} {
+			if abs(d) > int(tolerance) {
+				return false
+			}
+		}
+		return true
+	}
+	uniformRow := func(y, x0, x1 int) bool {
+		for x := x0; x < x1; x++ {
+			if !similar(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	uniformColumn := func(x, y0, y1 int) bool {
+		for y := y0; y < y1; y++ {
+			if !similar(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	r := rect
+	for r.Min.Y < r.Max.Y && uniformRow(r.Min.Y, r.Min.X, r.Max.X) {
+		r.Min.Y++
+	}
+	if r.Min.Y == r.Max.Y {
+		return rect
+	}
+	for uniformRow(r.Max.Y-1, r.Min.X, r.Max.X) {
+		r.Max.Y--
+	}
+	for uniformColumn(r.Min.X, r.Min.Y, r.Max.Y) {
+		r.Min.X++
+	}
+	for uniformColumn(r.Max.X-1, r.Min.Y, r.Max.Y) {
+		r.Max.X--
+	}
+	return r
+}
+
+// centeredRect returns the largest rectangle centered in rect with given
+// ratio of width to height
+func centeredRect(rect image.Rectangle, ratio float64) image.Rectangle {
+	w, h := rect.Dx(), rect.Dy()
+	if float64(w) > float64(h)*ratio {
+		w = maxInt(int(math.Round(float64(h)*ratio)), 1)
+	} else {
+		h = maxInt(int(math.Round(float64(w)/ratio)), 1)
+	}
+	min := rect.Min.Add(image.Pt((rect.Dx()-w)/2, (rect.Dy()-h)/2))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(w, h))}
+}
+
+// cropRect copies rect of the input into a new image of the same type
+func cropRect(input image.Image, rect image.Rectangle) image.Image {
+	offset := rect.Min.Sub(input.Bounds().Min)
+	return remap(input, rect.Dx(), rect.Dy(), func(x, y int) (int, int) {
+		return x + offset.X, y + offset.Y
+	})
+}
+
+//Pad is a config struct
+//Configuration contains:
+//  Top, Right, Bottom, Left - widths of added borders in pixels
+//  Fill - color of borders in hex format (e.g. #b690d9), transparent when
+//      empty
+//  Replicate - if true then borders repeat edge pixels of the image
+//      instead of Fill color
+type Pad struct {
+	Top       int
+	Right     int
+	Bottom    int
+	Left      int
+	Fill      string
+	Replicate bool
+}
+
+//Convert takes an image as an input and returns padded image of the same type
+func (config Pad) Convert(input image.Image) image.Image {
+	bounds := input.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	width := w + maxInt(config.Left, 0) + maxInt(config.Right, 0)
+	height := h + maxInt(config.Top, 0) + maxInt(config.Bottom, 0)
+
+	var fill color.Color = color.RGBA{}
+	if c, err := parseHexColor(config.Fill); err == nil {
+		fill = c
+	}
+
+	output := newImageLike(input, image.Rect(0, 0, width, height))
+	parallelBands(height, func(from, to int) {
+		for y := from; y < to; y++ {
+			sy := y - maxInt(config.Top, 0)
+			for x := 0; x < width; x++ {
+				sx := x - maxInt(config.Left, 0)
+				inside := sx >= 0 && sy >= 0 && sx < w && sy < h
+				switch {
+				case inside:
+					output.Set(x, y, input.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+				case config.Replicate && w > 0 && h > 0:
+					sx, sy := minInt(maxInt(sx, 0), w-1), minInt(maxInt(sy, 0), h-1)
+					output.Set(x, y, input.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+				default:
+					output.Set(x, y, fill)
+				}
+			}
+		}
+	})
+	return output
+}
+
+//SmartCrop is a config struct
+//Configuration contains:
+//  Width, Height - size of the cropped region, limited by the size of the
+//      input. 0 is calculated from aspect ratio of the input
+//Region with the highest density of edges and entropy of luminosity is
+//kept, which usually contains the subject of the photo
+type SmartCrop struct {
+	Width  int
+	Height int
+}
+
+//Convert takes an image as an input and returns cropped image of the same type
+func (config SmartCrop) Convert(input image.Image) image.Image {
+	bounds := input.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	cw, ch := config.Width, config.Height
+	switch {
+	case cw <= 0 && ch <= 0:
+		cw, ch = w, h
+	case cw <= 0:
+		cw = int(math.Round(float64(w*ch) / float64(h)))
+	case ch <= 0:
+		ch = int(math.Round(float64(h*cw) / float64(w)))
+	}
+	cw, ch = maxInt(minInt(cw, w), 1), maxInt(minInt(ch, h), 1)
+	if w == 0 || h == 0 {
+		return cropRect(input, bounds)
+	}
+
+	gray := Gray{Algorithm: GrayAlgorithms.Luminosity}.Convert(input)
+	edges := newIntegral(gradientMagnitude(gray), w, h, false)
+
+	// about 10 positions per axis are compared, entropy is too slow for
+	// every position
+	stepX, stepY := maxInt((w-cw)/10, 1), maxInt((h-ch)/10, 1)
+	best, bestScore := image.Rect(0, 0, cw, ch), -1.0
+	for y := 0; y <= h-ch; y += stepY {
+		for x := 0; x <= w-cw; x += stepX {
+			r := image.Rect(x, y, x+cw, y+ch)
+			// both edge density and entropy are scaled to range 0-1
+			density := edges.rect(r) / float64(cw*ch) / 255
+			entropy := histogramGray(gray.SubImage(r.Add(bounds.Min)).(*image.Gray)).Entropy() / 8
+			if score := density + entropy; score > bestScore {
+				best, bestScore = r, score
+			}
+		}
+	}
+	return cropRect(input, best.Add(bounds.Min))
+}
+
+// gradientMagnitude returns sum of absolute central differences of every
+// pixel row by row
+func gradientMagnitude(img *image.Gray) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	at := func(x, y int) float64 {
+		x, y = minInt(maxInt(x, 0), w-1), minInt(maxInt(y, 0), h-1)
+		return float64(img.Pix[y*img.Stride+x])
+	}
+	values := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			values[y*w+x] = (math.Abs(at(x+1, y)-at(x-1, y)) + math.Abs(at(x, y+1)-at(x, y-1))) / 2
+		}
+	}
+	return values
+}
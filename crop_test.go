@@ -0,0 +1,104 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCropRect(t *testing.T) {
+	out := Crop{Rect: image.Rect(1, 1, 3, 9)}.Convert(numberedImage(4, 3)).(*image.Gray)
+
+	if size := out.Bounds().Size(); size.X != 2 || size.Y != 2 {
+		t.Errorf("Invalid size of cropped image, got: %v, want: (2,2).", size)
+	}
+	if v := out.GrayAt(1, 1).Y; v != 22 {
+		t.Errorf("Invalid level of cropped image, got: %d, want: %d.", v, 22)
+	}
+}
+
+func TestCropAspectRatio(t *testing.T) {
+	out := Crop{AspectRatio: 1}.Convert(numberedImage(6, 2)).(*image.Gray)
+
+	if size := out.Bounds().Size(); size.X != 2 || size.Y != 2 {
+		t.Errorf("Invalid size of cropped image, got: %v, want: (2,2).", size)
+	}
+	if v := out.GrayAt(0, 0).Y; v != 2 {
+		t.Errorf("Cropped region should be centered, got: %d, want: %d.", v, 2)
+	}
+}
+
+func TestCropTrim(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 8, 6))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 250}})
+	input.SetGray(2, 1, color.Gray{Y: 0})
+	input.SetGray(5, 3, color.Gray{Y: 0})
+	// noise within tolerance
+	input.SetGray(7, 5, color.Gray{Y: 245})
+
+	out := Crop{Trim: true, Tolerance: 10}.Convert(input)
+	if bounds := out.Bounds(); bounds != image.Rect(0, 0, 4, 3) {
+		t.Errorf("Invalid trimmed bounds, got: %v, want: %v.", bounds, image.Rect(0, 0, 4, 3))
+	}
+
+	uniform := image.NewGray(image.Rect(0, 0, 3, 3))
+	if bounds := (Crop{Trim: true}).Convert(uniform).Bounds(); bounds != uniform.Bounds() {
+		t.Errorf("Uniform image should not be trimmed, got: %v.", bounds)
+	}
+}
+
+func TestPad(t *testing.T) {
+	input := numberedImage(2, 2)
+
+	filled := Pad{Top: 1, Left: 2, Fill: "#ffffff"}.Convert(input).(*image.Gray)
+	if size := filled.Bounds().Size(); size.X != 4 || size.Y != 3 {
+		t.Errorf("Invalid size of padded image, got: %v, want: (4,3).", size)
+	}
+	if border, inside := filled.GrayAt(0, 0).Y, filled.GrayAt(3, 2).Y; border != 0xFF || inside != 11 {
+		t.Errorf("Invalid padded levels, got: %d and %d, want: %d and %d.", border, inside, 0xFF, 11)
+	}
+
+	replicated := Pad{Right: 2, Bottom: 1, Replicate: true}.Convert(input).(*image.Gray)
+	if corner := replicated.GrayAt(3, 2).Y; corner != 11 {
+		t.Errorf("Invalid replicated level, got: %d, want: %d.", corner, 11)
+	}
+}
+
+func TestSmartCrop(t *testing.T) {
+	// flat image with a textured patch in the bottom right corner
+	input := image.NewGray(image.Rect(0, 0, 60, 40))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 128}})
+	for y := 25; y < 40; y++ {
+		for x := 40; x < 60; x++ {
+			input.SetGray(x, y, color.Gray{Y: uint8((x*37 + y*91) % 256)})
+		}
+	}
+
+	out := SmartCrop{Width: 20, Height: 20}.Convert(input).(*image.Gray)
+	if size := out.Bounds().Size(); size.X != 20 || size.Y != 20 {
+		t.Errorf("Invalid size of cropped image, got: %v, want: (20,20).", size)
+	}
+	if v := out.GrayAt(19, 19).Y; v == 128 {
+		t.Errorf("Cropped region should contain the textured patch.")
+	}
+}
+
+func TestSmartCropSubImage(t *testing.T) {
+	// smooth ramp on the left of the sub image has many levels, the rest
+	// is flat, so the ramp is found by entropy of windows of the sub image
+	input := image.NewGray(image.Rect(0, 0, 80, 20))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 128}})
+	for y := 0; y < 20; y++ {
+		for x := 20; x < 40; x++ {
+			input.SetGray(x, y, color.Gray{Y: uint8(8*(x-20) + y)})
+		}
+	}
+	sub := input.SubImage(image.Rect(20, 0, 80, 20))
+
+	out := SmartCrop{Width: 20, Height: 20}.Convert(sub).(*image.Gray)
+	for _, x := range []int{0, 19} {
+		if v, expected := out.GrayAt(x, 10).Y, input.GrayAt(20+x, 10).Y; v != expected {
+			t.Errorf("Invalid level of cropped sub image at %d, got: %d, want: %d.", x, v, expected)
+		}
+	}
+}
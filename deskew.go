@@ -0,0 +1,107 @@
+package pixl
+
+import (
+	"image"
+	"math"
+)
+
+//Deskew is a config struct
+//Configuration contains:
+//  MaxAngle - maximal detected skew in degrees (default 15)
+//  Algorithm - threshold algorithm used to find dark text
+//      (default Otsu, see pixl.ThresholdAlgorithms)
+//  Interpolation - sampling of the rotation (default bilinear,
+//      see pixl.Interpolations)
+//  Fill - color of uncovered corners in hex format (default #ffffff)
+type Deskew struct {
+	MaxAngle      float64
+	Algorithm     thresholdAlgoName
+	Interpolation interpolationName
+	Fill          string
+}
+
+//Convert takes an image as an input and returns image rotated so its
+//lines of text are horizontal
+func (config Deskew) Convert(input image.Image) image.Image {
+	output, _ := config.ConvertAngle(input)
+	return output
+}
+
+//ConvertAngle works like Convert, but also returns detected skew, which
+//is a clockwise angle in degrees the content is rotated by
+func (config Deskew) ConvertAngle(input image.Image) (image.Image, float64) {
+	angle := config.Angle(input)
+	fill := config.Fill
+	if fill == "" {
+		fill = "#ffffff"
+	}
+	return Rotate{Angle: -angle, Interpolation: config.Interpolation, Fill: fill}.Convert(input), angle
+}
+
+//Angle takes an image as an input and returns its skew, which is
+//a clockwise angle in degrees the content is rotated by. Skew is the angle
+//of the sharpest projection profile of dark pixels, at which rows of text
+//fall into the fewest bins
+func (config Deskew) Angle(input image.Image) float64 {
+	algorithm := config.Algorithm
+	if algorithm == "" {
+		algorithm = ThresholdAlgorithms.Otsu
+	}
+	binary := Threshold{Algorithm: algorithm}.Convert(input)
+	w, h := binary.Bounds().Dx(), binary.Bounds().Dy()
+
+	// dark pixels relative to the center, large images are subsampled
+	var xs, ys []float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if binary.Pix[y*binary.Stride+x] == 0 {
+				xs = append(xs, float64(x)-float64(w)/2)
+				ys = append(ys, float64(y)-float64(h)/2)
+			}
+		}
+	}
+	if len(xs) == 0 || len(xs) == w*h {
+		return 0
+	}
+	if step := len(xs) / 200000; step > 1 {
+		for i := 0; i*step < len(xs); i++ {
+			xs[i], ys[i] = xs[i*step], ys[i*step]
+		}
+		xs, ys = xs[:len(xs)/step], ys[:len(ys)/step]
+	}
+
+	radius := int(math.Ceil(math.Hypot(float64(w), float64(h))/2)) + 1
+	bins := make([]int, 2*radius+1)
+	score := func(angle float64) float64 {
+		for i := range bins {
+			bins[i] = 0
+		}
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for i := range xs {
+			bins[int(math.Round(-xs[i]*sin+ys[i]*cos))+radius]++
+		}
+		sum := 0.0
+		for _, amount := range bins {
+			sum += float64(amount * amount)
+		}
+		return sum
+	}
+
+	// coarse search in whole degrees refined around the best one
+	maxAngle := config.MaxAngle
+	if maxAngle <= 0 {
+		maxAngle = 15
+	}
+	best, bestScore := 0.0, score(0)
+	search := func(from, to, step float64) {
+		for angle := from; angle <= to+step/2; angle += step {
+			if s := score(angle); s > bestScore {
+				best, bestScore = angle, s
+			}
+		}
+	}
+	search(-maxAngle, maxAngle, 1)
+	search(math.Max(best-1, -maxAngle), math.Min(best+1, maxAngle), 0.1)
+	search(math.Max(best-0.1, -maxAngle), math.Min(best+0.1, maxAngle), 0.01)
+	return math.Round(best*100) / 100
+}
@@ -0,0 +1,47 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// generateLines returns white page with dashed rows of dark "text"
+func generateLines() *image.Gray {
+	input := image.NewGray(image.Rect(0, 0, 200, 160))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 0xF0}})
+	for y := 30; y < 130; y += 16 {
+		for x := 30; x < 170; x++ {
+			if x%12 < 9 {
+				input.SetGray(x, y, color.Gray{Y: 0x20})
+				input.SetGray(x, y+1, color.Gray{Y: 0x20})
+			}
+		}
+	}
+	return input
+}
+
+func TestDeskewAngle(t *testing.T) {
+	for _, skew := range []float64{-6, 0, 3.5} {
+		rotated := Rotate{Angle: skew, Fill: "#f0f0f0"}.Convert(generateLines())
+		if angle := (Deskew{}).Angle(rotated); math.Abs(angle-skew) > 0.3 {
+			t.Errorf("Invalid detected skew, got: %v, want: %v.", angle, skew)
+		}
+	}
+}
+
+func TestDeskewConvert(t *testing.T) {
+	rotated := Rotate{Angle: 5, Fill: "#f0f0f0"}.Convert(generateLines())
+	output, angle := Deskew{}.ConvertAngle(rotated)
+
+	if size := output.Bounds().Size(); size != rotated.Bounds().Size() {
+		t.Errorf("Deskew should keep size of the image, got: %v, want: %v.", size, rotated.Bounds().Size())
+	}
+	if residual := (Deskew{}).Angle(output); math.Abs(residual) > 0.3 {
+		t.Errorf("Invalid skew after correction of %v, got: %v, want: 0.", angle, residual)
+	}
+	if _, _, _, a := output.At(0, 0).RGBA(); a != 0xFFFF {
+		t.Errorf("Uncovered corners should be filled, got alpha: %d.", a)
+	}
+}
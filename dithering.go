@@ -3,6 +3,8 @@ package pixl
 import (
 	"image"
 	"image/color"
+	"image/draw"
+	"math"
 )
 
 //Dithering is a config struct
@@ -11,9 +13,19 @@ type Dithering struct {
 
 //Convert takes an image as an input and returns dithered image
 func (dithering Dithering) Convert(input image.Image) image.Image {
-	grayInput := Gray{
+	gray := Gray{
 		Algorithm: GrayAlgorithms.Luminosity,
-	}.Convert(input)
+	}
+
+	var grayInput draw.Image
+	var threshold float32
+	if is16Bit(input) {
+		gray16 := gray.ConvertGray16(input)
+		grayInput, threshold = gray16, float32(calculateThreshold16(gray16))
+	} else {
+		gray8 := gray.Convert(input)
+		grayInput, threshold = gray8, float32(calculateThreshold(gray8))*0x101
+	}
 	bounds := grayInput.Bounds()
 	w, h := bounds.Max.X, bounds.Max.Y
 
@@ -22,10 +34,9 @@ func (dithering Dithering) Convert(input image.Image) image.Image {
 		matrix[x] = make([]float32, h)
 		for y := 0; y < h; y++ {
 			r, _, _, _ := grayInput.At(x, y).RGBA()
-			matrix[x][y] = float32(r >> 8)
+			matrix[x][y] = float32(r)
 		}
 	}
-	threshold := calculateThreshold(grayInput)
 
 	for y := 0; y < h-1; y++ {
 		for x := 1; x < w-1; x++ {
@@ -42,17 +53,18 @@ func (dithering Dithering) Convert(input image.Image) image.Image {
 
 	for x := 0; x < w; x++ {
 		for y := 0; y < h; y++ {
-			col := color.Gray{Y: uint8(matrix[x][y])}
-			grayInput.Set(x, y, col)
+			value := float32(math.Max(0, math.Min(0xFFFF, float64(matrix[x][y]))))
+			grayInput.Set(x, y, color.Gray16{Y: uint16(value)})
 		}
 	}
 
 	return grayInput
 }
 
-func toBlackOrWhite(in float32, threshold uint8) float32 {
-	if in < float32(threshold) {
+// toBlackOrWhite works on 16-bit values, threshold is 16-bit level as well
+func toBlackOrWhite(in float32, threshold float32) float32 {
+	if in < threshold {
 		return 0
 	}
-	return 255
+	return 0xFFFF
 }
@@ -0,0 +1,62 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDithering16Bit(t *testing.T) {
+	size := 10
+	input := image.NewGray16(image.Rect(0, 0, size, size))
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			// both halves have the same high byte, only 16-bit precision separates them
+			if y < 3 {
+				input.SetGray16(x, y, color.Gray16{Y: 0x8010})
+			} else {
+				input.SetGray16(x, y, color.Gray16{Y: 0x80F0})
+			}
+		}
+	}
+
+	out, ok := Dithering{}.Convert(input).(*image.Gray16)
+	if !ok {
+		t.Fatalf("16-bit image should be dithered into *image.Gray16.")
+	}
+
+	// level splits the halves, so middle gray of both is dithered into
+	// evenly spread black and white pixels. Pixels at the left, right and
+	// bottom edges are not dithered
+	whitePixels := 0
+	for x := 1; x < size-1; x++ {
+		for y := 0; y < size-1; y++ {
+			switch out.Gray16At(x, y).Y {
+			case 0xFFFF:
+				whitePixels++
+			case 0:
+			default:
+				t.Fatalf("Dithered pixel should be black or white, got: %#x.", out.Gray16At(x, y).Y)
+			}
+		}
+	}
+	if expected := 36; whitePixels != expected {
+		t.Errorf("Invalid number of white pixels in image, got: %d, want: %d.", whitePixels, expected)
+	}
+}
+
+func TestDitheringClamp(t *testing.T) {
+	input := image.NewGray16(image.Rect(0, 0, 3, 2))
+	for i, v := range []uint16{0xFFFF, 0x4000, 0xFFFF, 0x5000, 0xFFFF, 0xFFFF} {
+		input.SetGray16(i%3, i/3, color.Gray16{Y: v})
+	}
+
+	// error of black pixel (1, 0) is spread into white neighbours, which
+	// stay white instead of overflowing
+	out := Dithering{}.Convert(input).(*image.Gray16)
+	for i, expected := range []uint16{0xFFFF, 0, 0xFFFF, 0x5C00, 0xFFFF, 0xFFFF} {
+		if v := out.Gray16At(i%3, i/3).Y; v != expected {
+			t.Errorf("Invalid value of pixel (%d, %d), got: %#x, want: %#x.", i%3, i/3, v, expected)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+//Equalize is a config struct
+//Configuration contains:
+//  Color - if true then luma of colors is equalized and colors are kept,
+//      otherwise luminosity grayscale is equalized
+type Equalize struct {
+	Color bool
+}
+
+//Convert takes an image as an input and returns image with equalized
+//histogram, *image.Gray or *image.RGBA when Color is true
+func (config Equalize) Convert(input image.Image) image.Image {
+	gray := lumaOf(input, config.Color)
+	table := equalizationTable(histogramGray(gray), 0)
+	traverseImage(gray, gray, lookup{table: table})
+
+	if config.Color {
+		return withLuma(input, gray)
+	}
+	return gray
+}
+
+//CLAHE is a config struct of Contrast Limited Adaptive Histogram Equalization
+//Configuration contains:
+//  TilesX, TilesY - amount of tiles equalized separately (default 8x8)
+//  ClipLimit - maximal height of histogram bin relative to average bin
+//      height, excess is spread over all bins (default 2)
+//  Color - if true then luma of colors is equalized and colors are kept,
+//      otherwise luminosity grayscale is equalized
+type CLAHE struct {
+	TilesX    int
+	TilesY    int
+	ClipLimit float64
+	Color     bool
+}
+
+//Convert takes an image as an input and returns image with locally equalized
+//histogram, *image.Gray or *image.RGBA when Color is true
+func (config CLAHE) Convert(input image.Image) image.Image {
+	gray := lumaOf(input, config.Color)
+	bounds := gray.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	tilesX, tilesY := config.TilesX, config.TilesY
+	if tilesX <= 0 {
+		tilesX = 8
+	}
+	if tilesY <= 0 {
+		tilesY = 8
+	}
+	tilesX, tilesY = maxInt(minInt(tilesX, w), 1), maxInt(minInt(tilesY, h), 1)
+	clipLimit := config.ClipLimit
+	if clipLimit <= 0 {
+		clipLimit = 2
+	}
+
+	// tables of tiles, tile (i, j) covers x from i*w/tilesX to (i+1)*w/tilesX
+	tables := make([][]*[256]uint8, tilesY)
+	for j := range tables {
+		tables[j] = make([]*[256]uint8, tilesX)
+		for i := range tables[j] {
+			tile := image.Rect(i*w/tilesX, j*h/tilesY, (i+1)*w/tilesX, (j+1)*h/tilesY).Add(bounds.Min)
+			hist := histogramGray(gray.SubImage(tile).(*image.Gray))
+			limit := int(math.Ceil(clipLimit * float64(tile.Dx()*tile.Dy()) / 256))
+			tables[j][i] = equalizationTable(hist, limit)
+		}
+	}
+
+	// position of pixel in grid of tile centers and weight of the next tile
+	position := func(v, size, tiles int) (int, int, float64) {
+		f := (float64(v)+0.5)*float64(tiles)/float64(size) - 0.5
+		first := int(math.Floor(f))
+		weight := f - float64(first)
+		if first < 0 {
+			return 0, 0, 0
+		}
+		if first >= tiles-1 {
+			return tiles - 1, tiles - 1, 0
+		}
+		return first, first + 1, weight
+	}
+
+	output := image.NewGray(bounds)
+	for y := 0; y < h; y++ {
+		j0, j1, wy := position(y, h, tilesY)
+		for x := 0; x < w; x++ {
+			i0, i1, wx := position(x, w, tilesX)
+			v := gray.Pix[y*gray.Stride+x]
+
+			top := (1-wx)*float64(tables[j0][i0][v]) + wx*float64(tables[j0][i1][v])
+			bottom := (1-wx)*float64(tables[j1][i0][v]) + wx*float64(tables[j1][i1][v])
+			output.Pix[y*output.Stride+x] = uint8(math.Round((1-wy)*top + wy*bottom))
+		}
+	}
+
+	if config.Color {
+		return withLuma(input, output)
+	}
+	return output
+}
+
+// equalizationTable maps levels so that cumulative histogram becomes linear.
+// Bins higher than clipLimit (if positive) are clipped and the excess is
+// spread evenly over all bins
+func equalizationTable(hist *Histogram, clipLimit int) *[256]uint8 {
+	bins := *hist
+	if clipLimit > 0 {
+		excess := 0
+		for t := range bins {
+			if bins[t] > clipLimit {
+				excess += bins[t] - clipLimit
+				bins[t] = clipLimit
+			}
+		}
+		for t := range bins {
+			bins[t] += excess / len(bins)
+			if t < excess%len(bins) {
+				bins[t]++
+			}
+		}
+	}
+
+	table := &[256]uint8{}
+	amount := bins.Total()
+	first, _ := nonZeroRange(bins[:])
+	if amount == 0 || amount == bins[first] {
+		for t := range table {
+			table[t] = uint8(t)
+		}
+		return table
+	}
+
+	cumulative := 0
+	for t := range bins {
+		cumulative += bins[t]
+		v := float64(cumulative-bins[first]) / float64(amount-bins[first]) * 255
+		table[t] = uint8(math.Round(math.Max(0, v)))
+	}
+	return table
+}
+
+// lumaOf returns Y of YCbCr of the image when color is true, otherwise
+// luminosity grayscale
+func lumaOf(input image.Image, color bool) *image.Gray {
+	if !color {
+		return Gray{Algorithm: GrayAlgorithms.Luminosity}.Convert(input)
+	}
+	output := image.NewGray(input.Bounds())
+	traverseImage(input, output, ycbcrLuma{})
+	return output
+}
+
+type ycbcrLuma struct{}
+
+func (config ycbcrLuma) transform(input color.Color) color.Color {
+	c := color.NRGBAModel.Convert(input).(color.NRGBA)
+	return color.Gray{Y: uint8(math.Round(luma(float64(c.R), float64(c.G), float64(c.B))))}
+}
+
+// withLuma replaces Y of YCbCr of the input with luma image, chroma and
+// alpha of the input are kept
+func withLuma(input image.Image, luma *image.Gray) *image.RGBA {
+	bounds := input.Bounds()
+	output := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(input.At(x, y)).(color.NRGBA)
+			_, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			r, g, b := color.YCbCrToRGB(luma.GrayAt(x, y).Y, cb, cr)
+			output.Set(x, y, color.NRGBA{R: r, G: g, B: b, A: c.A})
+		}
+	}
+	return output
+}
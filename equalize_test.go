@@ -0,0 +1,94 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// narrowImage has 4 columns of levels 100-103
+func narrowImage() *image.Gray {
+	input := image.NewGray(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			input.SetGray(x, y, color.Gray{Y: uint8(100 + x)})
+		}
+	}
+	return input
+}
+
+func TestEqualize(t *testing.T) {
+	out := Equalize{}.Convert(narrowImage()).(*image.Gray)
+
+	for x, expected := range []uint8{0, 85, 170, 255} {
+		if v := out.GrayAt(x, 0).Y; v != expected {
+			t.Errorf("Invalid equalized level, got: %d, want: %d.", v, expected)
+		}
+	}
+}
+
+func TestEqualizeConstant(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 2))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 77}})
+	out := Equalize{}.Convert(input).(*image.Gray)
+
+	if v := out.GrayAt(1, 1).Y; v != 77 {
+		t.Errorf("Invalid level of constant image, got: %d, want: %d.", v, 77)
+	}
+}
+
+func TestEqualizeColor(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{R: 110, G: 110, B: 110, A: 0x80})
+	out := Equalize{Color: true}.Convert(input).(*image.RGBA)
+
+	dark := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA)
+	if dark != (color.NRGBA{R: 0, G: 0, B: 0, A: 0xFF}) {
+		t.Errorf("Invalid dark color, got: %v.", dark)
+	}
+	bright := color.NRGBAModel.Convert(out.At(1, 0)).(color.NRGBA)
+	if bright.R != 0xFF || bright.G != 0xFF || bright.B != 0xFF || bright.A != 0x80 {
+		t.Errorf("Invalid bright color, got: %v.", bright)
+	}
+}
+
+func TestCLAHESingleTile(t *testing.T) {
+	input, _ := generateUnevenDocument()
+	global := Equalize{}.Convert(input).(*image.Gray)
+	local := CLAHE{TilesX: 1, TilesY: 1, ClipLimit: 256}.Convert(input).(*image.Gray)
+
+	for i := range global.Pix {
+		if global.Pix[i] != local.Pix[i] {
+			t.Errorf("Single tile without clipping should equal global equalization, got: %d, want: %d.", local.Pix[i], global.Pix[i])
+			break
+		}
+	}
+}
+
+func TestCLAHEClipLimit(t *testing.T) {
+	// clipping spreads the 4 equal bins over the whole histogram, which
+	// limits the contrast
+	out := CLAHE{TilesX: 1, TilesY: 1, ClipLimit: 1}.Convert(narrowImage()).(*image.Gray)
+	min, max := out.GrayAt(0, 0).Y, out.GrayAt(3, 0).Y
+
+	if min >= max || max-min == 255 {
+		t.Errorf("Invalid clipped range, got: [%d, %d].", min, max)
+	}
+}
+
+func TestHistogramGraySubImage(t *testing.T) {
+	tile := narrowImage().SubImage(image.Rect(2, 0, 4, 4)).(*image.Gray)
+	hist := histogramGray(tile)
+
+	if hist[102] != 4 || hist[103] != 4 || hist.Total() != 8 {
+		t.Errorf("Invalid histogram of sub image, got: %d, %d, %d, want: 4, 4, 8.", hist[102], hist[103], hist.Total())
+	}
+}
+
+func BenchmarkCLAHE(b *testing.B) {
+	img := generateImage()
+	for i := 0; i < b.N; i++ {
+		CLAHE{}.Convert(img)
+	}
+}
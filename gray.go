@@ -3,44 +3,113 @@ package pixl
 import (
 	"image"
 	"image/color"
+	"math"
 )
 
 type grayAlgoName string
 
 type grayAlgoList struct {
-	Lightness  grayAlgoName
-	Average    grayAlgoName
-	Luminosity grayAlgoName
+	Lightness    grayAlgoName
+	Average      grayAlgoName
+	Luminosity   grayAlgoName
+	Red          grayAlgoName
+	Green        grayAlgoName
+	Blue         grayAlgoName
+	Alpha        grayAlgoName
+	Maximum      grayAlgoName
+	Minimum      grayAlgoName
+	Value        grayAlgoName
+	LabLightness grayAlgoName
 }
 
 // GrayAlgorithms consists of a list of algorithms that can be used as
 // algorithm type in pixl.Gray struct. e.g.
 // pixl.Gray{Algorithm: pixl.GrayAlgorithms.Lithtness}
+//
+// Lightness is the HSL lightness, Value is the HSV value (same as Maximum),
+// LabLightness is the CIE L*a*b* L* component scaled to 0-255.
+// Red, Green, Blue and Alpha extract a single channel, while Maximum and
+// Minimum are max and min decomposition of RGB channels.
 var GrayAlgorithms = &grayAlgoList{
-	Lightness:  "lightness",
-	Average:    "average",
-	Luminosity: "luminosity",
+	Lightness:    "lightness",
+	Average:      "average",
+	Luminosity:   "luminosity",
+	Red:          "red",
+	Green:        "green",
+	Blue:         "blue",
+	Alpha:        "alpha",
+	Maximum:      "maximum",
+	Minimum:      "minimum",
+	Value:        "value",
+	LabLightness: "lab-lightness",
 }
 
 //Gray is a config struct
+//Configuration contains:
+//  Algorithm - grayscale Algorithm used to convert image
+//  Alpha - policy of handling transparent pixels (see pixl.AlphaPolicies)
+//  Background - color in hex format (e.g. #b690d9) used with Composite policy
 type Gray struct {
-	Algorithm grayAlgoName
+	Algorithm  grayAlgoName
+	Alpha      alphaPolicyName
+	Background string
 }
 
 //Convert takes an image as an input and returns grayscale of the image
 func (config Gray) Convert(input image.Image) *image.Gray {
 	output := image.NewGray(input.Bounds())
+	traverseImage(input, output, config.transformer())
+	return output
+}
 
-	if config.Algorithm == "lightness" {
-		traverseImage(input, output, grayLightness{})
-	} else if config.Algorithm == "average" {
-		traverseImage(input, output, grayAverage{})
-	} else { //if conf.Algorithm == "luminosity"
-		traverseImage(input, output, grayLuminosity{})
-	}
+//ConvertGray16 takes an image as an input and returns 16-bit grayscale of
+//the image, keeping precision of 16-bit sources (e.g. PNG or TIFF)
+func (config Gray) ConvertGray16(input image.Image) *image.Gray16 {
+	output := image.NewGray16(input.Bounds())
+	traverseImage(input, output, config.transformer())
 	return output
 }
 
+//ConvertAlpha takes an image as an input and returns grayscale of the image
+//with alpha channel of the input, unless Alpha policy composites it away
+func (config Gray) ConvertAlpha(input image.Image) *image.NRGBA {
+	if !config.Alpha.keepsAlpha() {
+		output := config.Convert(input)
+		return withAlpha(output, output)
+	}
+	// levels combined with alpha channel must not be premultiplied
+	config.Alpha = AlphaPolicies.Preserve
+	return withAlpha(config.Convert(input), input)
+}
+
+func (config Gray) transformer() transformer {
+	return newAlphaTransformer(config.Alpha, config.Background, config.algorithm())
+}
+
+func (config Gray) algorithm() transformer {
+	switch config.Algorithm {
+	case GrayAlgorithms.Lightness:
+		return grayLightness{}
+	case GrayAlgorithms.Average:
+		return grayAverage{}
+	case GrayAlgorithms.Red:
+		return grayChannel{channel: 0}
+	case GrayAlgorithms.Green:
+		return grayChannel{channel: 1}
+	case GrayAlgorithms.Blue:
+		return grayChannel{channel: 2}
+	case GrayAlgorithms.Alpha:
+		return grayChannel{channel: 3}
+	case GrayAlgorithms.Maximum, GrayAlgorithms.Value:
+		return grayDecomposition{maximum: true}
+	case GrayAlgorithms.Minimum:
+		return grayDecomposition{maximum: false}
+	case GrayAlgorithms.LabLightness:
+		return grayLabLightness{}
+	}
+	return grayLuminosity{}
+}
+
 type grayLightness struct{}
 
 func (config grayLightness) transform(input color.Color) color.Color {
@@ -63,8 +132,8 @@ func (config grayLightness) transform(input color.Color) color.Color {
 	_max := max(r, max(g, b))
 	_min := min(r, min(g, b))
 	result := (_max + _min) / 2
-	return color.Gray{
-		Y: uint8(result >> 8),
+	return color.Gray16{
+		Y: uint16(result),
 	}
 }
 
@@ -74,8 +143,8 @@ func (config grayAverage) transform(input color.Color) color.Color {
 	r, g, b, _ := input.RGBA()
 	result := (r + g + b) / 3
 
-	return color.Gray{
-		Y: uint8(result >> 8),
+	return color.Gray16{
+		Y: uint16(result),
 	}
 }
 
@@ -85,7 +154,50 @@ func (config grayLuminosity) transform(input color.Color) color.Color {
 	r, g, b, _ := input.RGBA()
 	result := uint32(0.21*float32(r) + 0.72*float32(g) + 0.07*float32(b))
 
-	return color.Gray{
-		Y: uint8(result >> 8),
+	return color.Gray16{
+		Y: uint16(result),
+	}
+}
+
+type grayChannel struct {
+	channel int
+}
+
+func (config grayChannel) transform(input color.Color) color.Color {
+	r, g, b, a := input.RGBA()
+	result := [4]uint32{r, g, b, a}[config.channel]
+
+	return color.Gray16{
+		Y: uint16(result),
+	}
+}
+
+type grayDecomposition struct {
+	maximum bool
+}
+
+func (config grayDecomposition) transform(input color.Color) color.Color {
+	r, g, b, _ := input.RGBA()
+
+	result := r
+	for _, c := range []uint32{g, b} {
+		if (config.maximum && c > result) || (!config.maximum && c < result) {
+			result = c
+		}
+	}
+
+	return color.Gray16{
+		Y: uint16(result),
+	}
+}
+
+type grayLabLightness struct{}
+
+func (config grayLabLightness) transform(input color.Color) color.Color {
+	r, g, b, _ := input.RGBA()
+	l, _, _ := rgbToLab(float64(r)/0xFFFF, float64(g)/0xFFFF, float64(b)/0xFFFF)
+
+	return color.Gray16{
+		Y: uint16(math.Round(l / 100 * 0xFFFF)),
 	}
 }
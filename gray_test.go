@@ -2,6 +2,7 @@ package pixl
 
 import (
 	"image"
+	"image/color"
 	_ "image/jpeg"
 	"testing"
 )
@@ -49,6 +50,64 @@ func TestGrayLuminosity(t *testing.T) {
 	}
 }
 
+func TestGrayChannels(t *testing.T) {
+	image := image.NewNRGBA(image.Rectangle{Max: image.Point{X: 1, Y: 1}})
+	inputColor, _ := parseHexColor("#FF400F")
+	image.Set(0, 0, inputColor)
+
+	tests := []struct {
+		algorithm grayAlgoName
+		expected  uint32
+	}{
+		{GrayAlgorithms.Red, 0xFF},
+		{GrayAlgorithms.Green, 0x40},
+		{GrayAlgorithms.Blue, 0x0F},
+		{GrayAlgorithms.Alpha, 0xFF},
+		{GrayAlgorithms.Maximum, 0xFF},
+		{GrayAlgorithms.Minimum, 0x0F},
+		{GrayAlgorithms.Value, 0xFF},
+	}
+
+	for _, test := range tests {
+		out := Gray{Algorithm: test.algorithm}.Convert(image)
+
+		r, _, _, _ := out.At(0, 0).RGBA()
+		r = r >> 8
+
+		if r != test.expected {
+			t.Errorf("Invalid output color for %s, got: %d, want: %d.", test.algorithm, r, test.expected)
+		}
+	}
+}
+
+func TestGrayLabLightness(t *testing.T) {
+	image := image.NewNRGBA(image.Rectangle{Max: image.Point{X: 3, Y: 1}})
+	image.Set(0, 0, color.Black)
+	image.Set(1, 0, color.White)
+	// sRGB 118 has L* of about 49.6
+	image.Set(2, 0, color.Gray{Y: 118})
+	out := Gray{Algorithm: GrayAlgorithms.LabLightness}.Convert(image)
+
+	for x, expected := range []uint32{0, 255, 127} {
+		r, _, _, _ := out.At(x, 0).RGBA()
+		r = r >> 8
+
+		if r != expected {
+			t.Errorf("Invalid output color, got: %d, want: %d.", r, expected)
+		}
+	}
+}
+
+func TestGrayConvertGray16(t *testing.T) {
+	image := image.NewRGBA64(image.Rectangle{Max: image.Point{X: 1, Y: 1}})
+	image.Set(0, 0, color.RGBA64{R: 0x1234, G: 0x1234, B: 0x1234, A: 0xFFFF})
+	out := Gray{Algorithm: GrayAlgorithms.Average}.ConvertGray16(image)
+
+	if y, expected := out.Gray16At(0, 0).Y, uint16(0x1234); y != expected {
+		t.Errorf("Invalid output color, got: %#x, want: %#x.", y, expected)
+	}
+}
+
 func generateImage() image.Image {
 	size := 10
 	image := image.NewNRGBA(image.Rectangle{Max: image.Point{X: size, Y: size}})
@@ -47,9 +47,9 @@ func (config Halftone) Convert(input image.Image) image.Image {
 		traverseImage(output, output, paintAll{color: color})
 	}
 
-	var grayInput *image.Gray
+	var grayInput image.Image
 	if config.Normalize {
-		grayInput = Normalize{}.Convert(input).(*image.Gray)
+		grayInput = Normalize{}.Convert(input)
 	} else {
 		grayInput = Gray{}.Convert(input)
 	}
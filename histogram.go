@@ -0,0 +1,434 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Histogram holds amount of pixels for every 8-bit gray level
+type Histogram [256]int
+
+// Histogram16 holds amount of pixels for every 16-bit gray level
+type Histogram16 [1 << 16]int
+
+// ColorHistogram holds separate 8-bit histograms of every channel.
+// Channels are not premultiplied by alpha
+type ColorHistogram struct {
+	Red, Green, Blue, Alpha Histogram
+}
+
+// NewHistogram returns histogram of luminosity grayscale of the image
+func NewHistogram(img image.Image) *Histogram {
+	gray, ok := img.(*image.Gray)
+	if !ok {
+		gray = Gray{Algorithm: GrayAlgorithms.Luminosity}.Convert(img)
+	}
+	return histogramGray(gray)
+}
+
+// NewHistogram16 returns histogram of 16-bit luminosity grayscale of the image
+func NewHistogram16(img image.Image) *Histogram16 {
+	gray, ok := img.(*image.Gray16)
+	if !ok {
+		gray = Gray{Algorithm: GrayAlgorithms.Luminosity}.ConvertGray16(img)
+	}
+
+	hist := &Histogram16{}
+	bounds := gray.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			hist[gray.Gray16At(x, y).Y]++
+		}
+	}
+	return hist
+}
+
+// NewColorHistogram returns histograms of red, green, blue and alpha channels
+func NewColorHistogram(img image.Image) *ColorHistogram {
+	hist := &ColorHistogram{}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			hist.Red[c.R]++
+			hist.Green[c.G]++
+			hist.Blue[c.B]++
+			hist.Alpha[c.A]++
+		}
+	}
+	return hist
+}
+
+// Total returns amount of pixels
+func (hist *Histogram) Total() int { return total(hist[:]) }
+
+// Mean returns mean gray level
+func (hist *Histogram) Mean() float64 { return meanOf(hist[:], 0, len(hist)-1) }
+
+// StdDev returns standard deviation of gray levels
+func (hist *Histogram) StdDev() float64 { return stdDev(hist[:]) }
+
+// Median returns the middle gray level
+func (hist *Histogram) Median() uint8 { return uint8(percentile(hist[:], 50)) }
+
+// Percentile returns the lowest gray level which is greater or equal to
+// p percent (0-100) of pixels
+func (hist *Histogram) Percentile(p float64) uint8 { return uint8(percentile(hist[:], p)) }
+
+// Entropy returns Shannon entropy of gray levels in bits
+func (hist *Histogram) Entropy() float64 { return entropy(hist[:]) }
+
+// Min returns the lowest gray level present in the image
+func (hist *Histogram) Min() uint8 {
+	first, _ := nonZeroRange(hist[:])
+	return uint8(first)
+}
+
+// Max returns the highest gray level present in the image
+func (hist *Histogram) Max() uint8 {
+	_, last := nonZeroRange(hist[:])
+	return uint8(last)
+}
+
+// Total returns amount of pixels
+func (hist *Histogram16) Total() int { return total(hist[:]) }
+
+// Mean returns mean gray level
+func (hist *Histogram16) Mean() float64 { return meanOf(hist[:], 0, len(hist)-1) }
+
+// StdDev returns standard deviation of gray levels
+func (hist *Histogram16) StdDev() float64 { return stdDev(hist[:]) }
+
+// Median returns the middle gray level
+func (hist *Histogram16) Median() uint16 { return uint16(percentile(hist[:], 50)) }
+
+// Percentile returns the lowest gray level which is greater or equal to
+// p percent (0-100) of pixels
+func (hist *Histogram16) Percentile(p float64) uint16 { return uint16(percentile(hist[:], p)) }
+
+// Entropy returns Shannon entropy of gray levels in bits
+func (hist *Histogram16) Entropy() float64 { return entropy(hist[:]) }
+
+// Min returns the lowest gray level present in the image
+func (hist *Histogram16) Min() uint16 {
+	first, _ := nonZeroRange(hist[:])
+	return uint16(first)
+}
+
+// Max returns the highest gray level present in the image
+func (hist *Histogram16) Max() uint16 {
+	_, last := nonZeroRange(hist[:])
+	return uint16(last)
+}
+
+// Reduce returns 8-bit histogram, every 8-bit level gathers 256 16-bit levels
+func (hist *Histogram16) Reduce() *Histogram {
+	reduced := &Histogram{}
+	for t, amount := range hist {
+		reduced[t>>8] += amount
+	}
+	return reduced
+}
+
+// Level returns threshold level computed from histogram with given algorithm.
+// Pixels lower or equal to the level belong to background (black) class.
+// Algorithms which are not based on global histogram (e.g. Static or local
+// ones) fall back to Otsu, like pixl.Threshold does for unknown algorithms
+func (hist *Histogram) Level(algorithm thresholdAlgoName) uint8 {
+	switch algorithm {
+	case ThresholdAlgorithms.Triangle:
+		return uint8(triangle(hist[:]))
+	case ThresholdAlgorithms.Kapur:
+		return uint8(kapur(hist[:]))
+	case ThresholdAlgorithms.Yen:
+		return uint8(yen(hist[:]))
+	case ThresholdAlgorithms.Li:
+		return uint8(li(hist[:]))
+	case ThresholdAlgorithms.Huang:
+		return uint8(huang(hist[:]))
+	case ThresholdAlgorithms.IsoData:
+		return uint8(isoData(hist[:]))
+	case ThresholdAlgorithms.Mean:
+		return uint8(meanLevel(hist[:]))
+	}
+	return uint8(otsu(hist[:], total(hist[:])))
+}
+
+// stdDev returns standard deviation of levels
+func stdDev(hist []int) float64 {
+	mean := meanOf(hist, 0, len(hist)-1)
+	sum, amount := 0.0, 0.0
+	for t, n := range hist {
+		d := float64(t) - mean
+		sum += d * d * float64(n)
+		amount += float64(n)
+	}
+	if amount == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / amount)
+}
+
+// percentile returns the lowest level reached by p percent of pixels
+func percentile(hist []int, p float64) int {
+	p = math.Max(0, math.Min(100, p))
+	limit := p / 100 * float64(total(hist))
+
+	cumulative := 0
+	for t, amount := range hist {
+		cumulative += amount
+		if cumulative > 0 && float64(cumulative) >= limit {
+			return t
+		}
+	}
+	return len(hist) - 1
+}
+
+// entropy returns Shannon entropy of levels in bits
+func entropy(hist []int) float64 {
+	amount := float64(total(hist))
+	result := 0.0
+	for _, n := range hist {
+		if n > 0 {
+			p := float64(n) / amount
+			result -= p * math.Log2(p)
+		}
+	}
+	return result
+}
+
+func total(hist []int) int {
+	sum := 0
+	for _, amount := range hist {
+		sum += amount
+	}
+	return sum
+}
+
+// nonZeroRange returns first and last bin containing any pixel
+func nonZeroRange(hist []int) (first, last int) {
+	first, last = 0, len(hist)-1
+	for first < last && hist[first] == 0 {
+		first++
+	}
+	for last > first && hist[last] == 0 {
+		last--
+	}
+	return first, last
+}
+
+// meanOf returns mean level of bins from first to last inclusive
+func meanOf(hist []int, first, last int) float64 {
+	sum, amount := 0.0, 0.0
+	for t := first; t <= last; t++ {
+		sum += float64(t * hist[t])
+		amount += float64(hist[t])
+	}
+	if amount == 0 {
+		return 0
+	}
+	return sum / amount
+}
+
+// meanLevel returns integer part of mean level of the image
+func meanLevel(hist []int) int {
+	return int(meanOf(hist, 0, len(hist)-1))
+}
+
+// isoData returns the lowest level lying halfway between mean of
+// background and mean of foreground (intermeans by Ridler and Calvard),
+// as scikit-image does
+func isoData(hist []int) int {
+	first, last := nonZeroRange(hist)
+	for t := first; t < last; t++ {
+		middle := (meanOf(hist, first, t) + meanOf(hist, t+1, last)) / 2
+		if middle >= float64(t) && middle < float64(t+1) {
+			return t
+		}
+	}
+	return first
+}
+
+// triangle draws a line from the end of the longer tail of histogram to its
+// peak and returns level of the bin most distant below that line (Zack's
+// method, as in scikit-image). Works well for unimodal histograms, e.g.
+// sparse text on white background
+func triangle(hist []int) int {
+	first, last := nonZeroRange(hist)
+	peak := first
+	for t := first; t <= last; t++ {
+		if hist[t] > hist[peak] {
+			peak = t
+		}
+	}
+
+	// the line starts at the last non-empty bin of the tail
+	end, direction := first, 1
+	if last-peak > peak-first {
+		end, direction = last, -1
+	}
+
+	// the line is fixed, so perpendicular distance is proportional to
+	// vertical distance of the bin below the line
+	level, maxDistance := peak, math.Inf(-1)
+	for t := end; t != peak; t += direction {
+		height := float64(hist[peak]) * float64(t-end) / float64(peak-end)
+		if distance := height - float64(hist[t]); distance > maxDistance {
+			maxDistance = distance
+			level = t
+		}
+	}
+	return level
+}
+
+// kapur returns level maximizing sum of entropies of background and
+// foreground (Kapur, Sahoo and Wong maximum entropy method)
+func kapur(hist []int) int {
+	amount := float64(total(hist))
+	if amount == 0 {
+		return 0
+	}
+
+	cumulative := make([]float64, len(hist))
+	sum := 0.0
+	for t := range hist {
+		sum += float64(hist[t]) / amount
+		cumulative[t] = sum
+	}
+
+	entropy := func(from, to int, weight float64) float64 {
+		result := 0.0
+		for t := from; t <= to; t++ {
+			if hist[t] > 0 {
+				p := float64(hist[t]) / amount / weight
+				result -= p * math.Log(p)
+			}
+		}
+		return result
+	}
+
+	first, last := nonZeroRange(hist)
+	level, maxEntropy := first, math.Inf(-1)
+	for t := first; t < last; t++ {
+		background, foreground := cumulative[t], 1-cumulative[t]
+		if background <= 0 || foreground <= 0 {
+			continue
+		}
+		if h := entropy(0, t, background) + entropy(t+1, len(hist)-1, foreground); h > maxEntropy {
+			maxEntropy = h
+			level = t
+		}
+	}
+	return level
+}
+
+// yen returns level maximizing Yen's correlation criterion
+func yen(hist []int) int {
+	amount := float64(total(hist))
+	if amount == 0 {
+		return 0
+	}
+
+	bins := len(hist)
+	cumulative := make([]float64, bins)
+	squares := make([]float64, bins)
+	sum, sumSquares := 0.0, 0.0
+	for t := range hist {
+		p := float64(hist[t]) / amount
+		sum += p
+		sumSquares += p * p
+		cumulative[t] = sum
+		squares[t] = sumSquares
+	}
+
+	level, maxCriterion := 0, math.Inf(-1)
+	for t := 0; t < bins-1; t++ {
+		background, foreground := cumulative[t], 1-cumulative[t]
+		backgroundSquares, foregroundSquares := squares[t], squares[bins-1]-squares[t]
+		if backgroundSquares <= 0 || foregroundSquares <= 0 {
+			continue
+		}
+		criterion := -math.Log(backgroundSquares*foregroundSquares) +
+			2*math.Log(background*foreground)
+		if criterion > maxCriterion {
+			maxCriterion = criterion
+			level = t
+		}
+	}
+	return level
+}
+
+// li returns level minimizing cross-entropy between image and its
+// thresholded version, found iteratively. Levels are shifted to start at 0
+// and the level is kept as float until it converges, as scikit-image does
+func li(hist []int) int {
+	first, last := nonZeroRange(hist)
+	if first == last {
+		return first
+	}
+	mean := func(from, to int) float64 {
+		return meanOf(hist, from, to) - float64(first)
+	}
+
+	level, next := -1.0, mean(first, last)
+	for i := 0; i < 1000 && math.Abs(next-level) > 0.5; i++ {
+		level = next
+		split := first + int(math.Floor(level))
+		backgroundMean, foregroundMean := mean(first, split), mean(split+1, last)
+		if backgroundMean <= 0 || foregroundMean <= 0 {
+			break
+		}
+		next = (backgroundMean - foregroundMean) /
+			(math.Log(backgroundMean) - math.Log(foregroundMean))
+	}
+	return first + int(math.Floor(next))
+}
+
+// huang returns level minimizing fuzzy entropy of membership of pixels
+// to mean of their class (Huang and Wang). Means of classes are rounded to
+// whole levels to read membership from a table, as ImageJ does
+func huang(hist []int) int {
+	first, last := nonZeroRange(hist)
+	if first == last {
+		return first
+	}
+
+	// cumulative amounts and sums of levels
+	amounts := make([]float64, last+1)
+	sums := make([]float64, last+1)
+	amount, sum := 0.0, 0.0
+	for t := first; t <= last; t++ {
+		amount += float64(hist[t])
+		sum += float64(t * hist[t])
+		amounts[t], sums[t] = amount, sum
+	}
+
+	// entropy of membership for every distance from class mean
+	c := float64(last - first)
+	entropies := make([]float64, last-first+1)
+	for distance := 1; distance < len(entropies); distance++ {
+		mu := 1 / (1 + float64(distance)/c)
+		entropies[distance] = -mu*math.Log(mu) - (1-mu)*math.Log(1-mu)
+	}
+
+	level, minEntropy := first, math.Inf(1)
+	for t := first; t <= last; t++ {
+		entropy := 0.0
+		mu := int(math.Floor(sums[t]/amounts[t] + 0.5))
+		for i := first; i <= t; i++ {
+			entropy += entropies[abs(i-mu)] * float64(hist[i])
+		}
+		if t < last {
+			mu = int(math.Floor((sums[last]-sums[t])/(amounts[last]-amounts[t]) + 0.5))
+			for i := t + 1; i <= last; i++ {
+				entropy += entropies[abs(i-mu)] * float64(hist[i])
+			}
+		}
+		if entropy < minEntropy {
+			minEntropy = entropy
+			level = t
+		}
+	}
+	return level
+}
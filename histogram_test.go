@@ -0,0 +1,212 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// bimodalHistogram has two equal triangular peaks centered at 50 and 200
+func bimodalHistogram() *Histogram {
+	hist := &Histogram{}
+	for _, peak := range []int{50, 200} {
+		for d := -5; d <= 5; d++ {
+			hist[peak+d] = 50 - 5*abs(d)
+		}
+	}
+	return hist
+}
+
+// unimodalHistogram looks like sparse dark text (20-30) on white paper (240)
+func unimodalHistogram() *Histogram {
+	hist := &Histogram{}
+	hist[240] = 1000
+	for t := 20; t <= 30; t++ {
+		hist[t] = 5
+	}
+	return hist
+}
+
+func TestHistogramLevel(t *testing.T) {
+	tests := []struct {
+		algorithm thresholdAlgoName
+		bimodal   uint8
+		unimodal  uint8
+	}{
+		// every level in the gap between classes is equally good, so the
+		// first one is returned: the end of the first peak or of the text
+		{ThresholdAlgorithms.Otsu, 55, 30},
+		{ThresholdAlgorithms.Kapur, 55, 30},
+		{ThresholdAlgorithms.Yen, 55, 30},
+		{ThresholdAlgorithms.Huang, 55, 30},
+		// the most distant empty bin is the one next to the peak
+		{ThresholdAlgorithms.Triangle, 56, 239},
+		// (50 + 200) / 2 and (25 + 240) / 2
+		{ThresholdAlgorithms.IsoData, 125, 132},
+		// levels shifted to start at 0 converge far from the gap
+		{ThresholdAlgorithms.Li, 88, 76},
+		// (1000 * 240 + 55 * 25) / 1055
+		{ThresholdAlgorithms.Mean, 125, 228},
+	}
+
+	for _, test := range tests {
+		if level := bimodalHistogram().Level(test.algorithm); level != test.bimodal {
+			t.Errorf("Invalid level of bimodal histogram for %s, got: %d, want: %d.", test.algorithm, level, test.bimodal)
+		}
+		if level := unimodalHistogram().Level(test.algorithm); level != test.unimodal {
+			t.Errorf("Invalid level of unimodal histogram for %s, got: %d, want: %d.", test.algorithm, level, test.unimodal)
+		}
+	}
+}
+
+// overlappingHistogram is a sum of two overlapping gaussians, dark one
+// centered at 70 and bright one at 165, cut to levels 20-230
+func overlappingHistogram() *Histogram {
+	hist := &Histogram{}
+	for t := 20; t <= 230; t++ {
+		x := float64(t)
+		hist[t] = int(math.Floor(3000*math.Exp(-(x-70)*(x-70)/(2*18*18)) +
+			1800*math.Exp(-(x-165)*(x-165)/(2*30*30)) + 0.5))
+	}
+	return hist
+}
+
+func TestHistogramLevelReference(t *testing.T) {
+	// reference levels computed with line by line ports of scikit-image 0.19
+	// threshold_* functions and of ImageJ Auto_Threshold MaxEntropy and
+	// Huang, which have no scikit-image counterpart. Float levels of Li and
+	// mean are truncated, as scikit-image selects pixels greater than them
+	tests := []struct {
+		algorithm thresholdAlgoName
+		expected  uint8
+	}{
+		{ThresholdAlgorithms.Otsu, 120},
+		{ThresholdAlgorithms.Kapur, 125},
+		{ThresholdAlgorithms.Yen, 115},
+		{ThresholdAlgorithms.Huang, 115},
+		{ThresholdAlgorithms.Triangle, 108},
+		{ThresholdAlgorithms.IsoData, 119},
+		// 109.86
+		{ThresholdAlgorithms.Li, 109},
+		// 116.73
+		{ThresholdAlgorithms.Mean, 116},
+	}
+
+	hist := overlappingHistogram()
+	for _, test := range tests {
+		if level := hist.Level(test.algorithm); level != test.expected {
+			t.Errorf("Invalid level of overlapping histogram for %s, got: %d, want: %d.", test.algorithm, level, test.expected)
+		}
+	}
+}
+
+func TestThresholdTriangle(t *testing.T) {
+	size := 20
+	input := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			switch {
+			case j == 10 && i < 10:
+				input.Set(i, j, color.Gray{Y: 30})
+			case j == 11 && i < 10:
+				// faint anti-aliased edge of the text
+				input.Set(i, j, color.Gray{Y: 180})
+			default:
+				input.Set(i, j, color.Gray{Y: 240})
+			}
+		}
+	}
+
+	out := Threshold{Algorithm: ThresholdAlgorithms.Triangle}.Convert(input)
+	blackPixels := 0
+	for _, v := range out.Pix {
+		if v == 0x00 {
+			blackPixels++
+		}
+	}
+
+	if expected := 20; blackPixels != expected {
+		t.Errorf("Invalid number of black pixels in image, got: %d, want: %d.", blackPixels, expected)
+	}
+}
+
+func TestHistogramStatistics(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 4, 1))
+	for x, v := range []uint8{10, 20, 20, 50} {
+		input.SetGray(x, 0, color.Gray{Y: v})
+	}
+	hist := NewHistogram(input)
+
+	if total := hist.Total(); total != 4 {
+		t.Errorf("Invalid total, got: %d, want: %d.", total, 4)
+	}
+	if mean := hist.Mean(); mean != 25 {
+		t.Errorf("Invalid mean, got: %v, want: %v.", mean, 25)
+	}
+	if deviation, expected := hist.StdDev(), math.Sqrt(225); deviation != expected {
+		t.Errorf("Invalid standard deviation, got: %v, want: %v.", deviation, expected)
+	}
+	if median := hist.Median(); median != 20 {
+		t.Errorf("Invalid median, got: %d, want: %d.", median, 20)
+	}
+	if p := hist.Percentile(10); p != 10 {
+		t.Errorf("Invalid 10th percentile, got: %d, want: %d.", p, 10)
+	}
+	if p := hist.Percentile(100); p != 50 {
+		t.Errorf("Invalid 100th percentile, got: %d, want: %d.", p, 50)
+	}
+	if entropy := hist.Entropy(); entropy != 1.5 {
+		t.Errorf("Invalid entropy, got: %v, want: %v.", entropy, 1.5)
+	}
+	if min, max := hist.Min(), hist.Max(); min != 10 || max != 50 {
+		t.Errorf("Invalid range, got: [%d, %d], want: [%d, %d].", min, max, 10, 50)
+	}
+}
+
+func TestHistogram16(t *testing.T) {
+	input := image.NewGray16(image.Rect(0, 0, 3, 1))
+	for x, v := range []uint16{0x1001, 0x10FF, 0x2000} {
+		input.SetGray16(x, 0, color.Gray16{Y: v})
+	}
+	hist := NewHistogram16(input)
+
+	if median := hist.Median(); median != 0x10FF {
+		t.Errorf("Invalid median, got: %#x, want: %#x.", median, 0x10FF)
+	}
+	if reduced := hist.Reduce(); reduced[0x10] != 2 || reduced[0x20] != 1 {
+		t.Errorf("Invalid reduced histogram, got: %d and %d, want: 2 and 1.", reduced[0x10], reduced[0x20])
+	}
+}
+
+func TestColorHistogram(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{R: 1, G: 5, B: 6, A: 0xFF})
+	hist := NewColorHistogram(input)
+
+	if hist.Red[1] != 2 || hist.Green[2] != 1 || hist.Blue[6] != 1 || hist.Alpha[0xFF] != 2 {
+		t.Errorf("Invalid color histogram, got: %d, %d, %d, %d, want: 2, 1, 1, 2.",
+			hist.Red[1], hist.Green[2], hist.Blue[6], hist.Alpha[0xFF])
+	}
+}
+
+func TestThresholdConvertLevels(t *testing.T) {
+	input, _ := generateUnevenDocument()
+
+	for _, algorithm := range []thresholdAlgoName{ThresholdAlgorithms.Otsu, ThresholdAlgorithms.Yen} {
+		_, levels := Threshold{Algorithm: algorithm}.ConvertLevels(input)
+		expected := NewHistogram(input).Level(algorithm)
+		if len(levels) != 1 || levels[0] != expected {
+			t.Errorf("Invalid levels for %s, got: %v, want: [%d].", algorithm, levels, expected)
+		}
+	}
+
+	if _, levels := (Threshold{Algorithm: ThresholdAlgorithms.MultiOtsu, Classes: 4}).ConvertLevels(input); len(levels) != 3 {
+		t.Errorf("Invalid amount of levels, got: %d, want: %d.", len(levels), 3)
+	}
+
+	if _, levels := (Threshold{Algorithm: ThresholdAlgorithms.Sauvola}).ConvertLevels(input); levels != nil {
+		t.Errorf("Local algorithm should not return levels, got: %v.", levels)
+	}
+}
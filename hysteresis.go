@@ -0,0 +1,56 @@
+package pixl
+
+import (
+	"image"
+)
+
+// convertHysteresis marks pixels above high level as foreground (white) and
+// grows them into connected pixels above low level
+func (config Threshold) convertHysteresis(img *image.Gray16) (*image.Gray, []uint16) {
+	low, high := config.levelRange()
+	bounds := img.Bounds()
+
+	strong := image.NewGray(bounds)
+	traverseImage(img, strong, threshold{level: high})
+	weak := image.NewGray(bounds)
+	traverseImage(img, weak, threshold{level: low})
+
+	neighbours := []image.Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	if config.Connectivity != 4 {
+		neighbours = append(neighbours, image.Point{-1, -1}, image.Point{1, -1}, image.Point{-1, 1}, image.Point{1, 1})
+	}
+
+	out := image.NewGray(bounds)
+	stack := []image.Point{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if strong.GrayAt(x, y).Y == 0xFF {
+				out.Pix[out.PixOffset(x, y)] = 0xFF
+				stack = append(stack, image.Point{x, y})
+			}
+		}
+	}
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, d := range neighbours {
+			n := p.Add(d)
+			if !n.In(bounds) {
+				continue
+			}
+			i := out.PixOffset(n.X, n.Y)
+			if out.Pix[i] == 0x00 && weak.Pix[weak.PixOffset(n.X, n.Y)] == 0xFF {
+				out.Pix[i] = 0xFF
+				stack = append(stack, n)
+			}
+		}
+	}
+
+	if config.InvertColors {
+		for i := range out.Pix {
+			out.Pix[i] = 255 - out.Pix[i]
+		}
+	}
+	return out, []uint16{low, high}
+}
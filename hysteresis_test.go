@@ -0,0 +1,57 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThresholdHysteresis(t *testing.T) {
+	// strong pixel at (0, 0) with faint line connected diagonally,
+	// faint pixel at (4, 4) is not connected to any strong pixel
+	input := image.NewGray(image.Rect(0, 0, 5, 5))
+	input.SetGray(0, 0, color.Gray{Y: 250})
+	input.SetGray(1, 1, color.Gray{Y: 120})
+	input.SetGray(2, 1, color.Gray{Y: 120})
+	input.SetGray(4, 4, color.Gray{Y: 120})
+
+	tests := []struct {
+		connectivity int
+		expected     int
+	}{
+		{8, 3},
+		{4, 1},
+	}
+
+	for _, test := range tests {
+		out, levels := Threshold{
+			Algorithm:    ThresholdAlgorithms.Hysteresis,
+			Levels:       &Range{Min: 100, Max: 200},
+			Connectivity: test.connectivity,
+		}.ConvertLevels(input)
+
+		whitePixels := 0
+		for _, v := range out.Pix {
+			if v == 0xFF {
+				whitePixels++
+			}
+		}
+
+		if whitePixels != test.expected {
+			t.Errorf("Invalid number of white pixels with %d-connectivity, got: %d, want: %d.", test.connectivity, whitePixels, test.expected)
+		}
+		if out.GrayAt(4, 4).Y != 0x00 {
+			t.Errorf("Weak pixel not connected to strong one should be black.")
+		}
+		if len(levels) != 2 || levels[0] != 100 || levels[1] != 200 {
+			t.Errorf("Invalid levels, got: %v, want: [100 200].", levels)
+		}
+	}
+
+	// low level 0 extends strong pixels into every pixel which isn't black
+	input.SetGray(3, 1, color.Gray{Y: 1})
+	out := Threshold{Algorithm: ThresholdAlgorithms.Hysteresis, Levels: &Range{Min: 0, Max: 200}}.Convert(input)
+	if out.GrayAt(3, 1).Y != 0xFF {
+		t.Errorf("Pixel above low level 0 connected to strong one should be white.")
+	}
+}
@@ -0,0 +1,48 @@
+package pixl
+
+import "image/color"
+
+// lookup maps 8-bit gray levels with a table
+type lookup struct {
+	table *[256]uint8
+}
+
+func (config lookup) transform(input color.Color) color.Color {
+	r, _, _, _ := input.RGBA()
+	return color.Gray{Y: config.table[r>>8]}
+}
+
+// channelLookup maps R, G, B channels with their tables and keeps alpha,
+// nil table keeps the channel unchanged
+type channelLookup struct {
+	tables [3]*[256]uint8
+}
+
+func (config channelLookup) transform(input color.Color) color.Color {
+	c := color.NRGBAModel.Convert(input).(color.NRGBA)
+	channels := [3]*uint8{&c.R, &c.G, &c.B}
+	for i, channel := range channels {
+		if config.tables[i] != nil {
+			*channel = config.tables[i][*channel]
+		}
+	}
+	return c
+}
+
+// newTable fills a table with values of function f for every level,
+// results are rounded and clamped to 0-255
+func newTable(f func(v float64) float64) *[256]uint8 {
+	table := &[256]uint8{}
+	for v := range table {
+		result := f(float64(v))
+		switch {
+		case result <= 0:
+			table[v] = 0
+		case result >= 255:
+			table[v] = 255
+		default:
+			table[v] = uint8(result + 0.5)
+		}
+	}
+	return table
+}
@@ -0,0 +1,84 @@
+package pixl
+
+import "image"
+
+//Match is a config struct of histogram matching (specification)
+//Configuration contains:
+//  Reference - image which histogram should be matched
+//  Target - histogram to match, used when Reference is nil. In Color mode
+//      it is matched by every channel
+//  Color - if true then every RGB channel is matched independently,
+//      otherwise luminosity grayscale is matched
+type Match struct {
+	Reference image.Image
+	Target    *Histogram
+	Color     bool
+}
+
+//Convert takes an image as an input and returns image with histogram
+//matching the reference, *image.Gray or *image.RGBA when Color is true.
+//Image is returned unchanged when neither Reference nor Target is set
+func (config Match) Convert(input image.Image) image.Image {
+	if config.Color {
+		return config.convertColor(input)
+	}
+
+	gray := Gray{Algorithm: GrayAlgorithms.Luminosity}.Convert(input)
+	target := config.Target
+	if config.Reference != nil {
+		target = histogramGray(Gray{Algorithm: GrayAlgorithms.Luminosity}.Convert(config.Reference))
+	}
+	if target == nil {
+		return gray
+	}
+
+	traverseImage(gray, gray, lookup{table: matchingTable(histogramGray(gray), target)})
+	return gray
+}
+
+func (config Match) convertColor(input image.Image) image.Image {
+	source := NewColorHistogram(input)
+	targets := [3]*Histogram{config.Target, config.Target, config.Target}
+	if config.Reference != nil {
+		reference := NewColorHistogram(config.Reference)
+		targets = [3]*Histogram{&reference.Red, &reference.Green, &reference.Blue}
+	}
+
+	output := image.NewRGBA(input.Bounds())
+	if targets[0] == nil {
+		traverseImage(input, output, channelLookup{})
+		return output
+	}
+
+	traverseImage(input, output, channelLookup{tables: [3]*[256]uint8{
+		matchingTable(&source.Red, targets[0]),
+		matchingTable(&source.Green, targets[1]),
+		matchingTable(&source.Blue, targets[2]),
+	}})
+	return output
+}
+
+// matchingTable maps every level of source to the lowest level of target
+// with cumulative share of pixels not lower than the source one
+func matchingTable(source, target *Histogram) *[256]uint8 {
+	table := &[256]uint8{}
+	sourceTotal, targetTotal := source.Total(), target.Total()
+	if sourceTotal == 0 || targetTotal == 0 {
+		for v := range table {
+			table[v] = uint8(v)
+		}
+		return table
+	}
+
+	sourceCumulative, targetCumulative, t := 0, target[0], 0
+	for v := range source {
+		sourceCumulative += source[v]
+		// compares sourceCumulative/sourceTotal with targetCumulative/targetTotal
+		for t < len(target)-1 && targetCumulative*sourceTotal < sourceCumulative*targetTotal {
+			t++
+			targetCumulative += target[t]
+		}
+		table[v] = uint8(t)
+	}
+	return table
+}
@@ -0,0 +1,61 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestMatchingTable(t *testing.T) {
+	source, target := &Histogram{}, &Histogram{}
+	source[10], source[20] = 1, 1
+	target[100], target[200] = 5, 5
+	table := matchingTable(source, target)
+
+	if table[10] != 100 || table[20] != 200 {
+		t.Errorf("Invalid matching table, got: %d and %d, want: %d and %d.", table[10], table[20], 100, 200)
+	}
+}
+
+func TestMatchReference(t *testing.T) {
+	reference := image.NewGray(image.Rect(0, 0, 4, 1))
+	for x, v := range []uint8{30, 60, 90, 120} {
+		reference.SetGray(x, 0, color.Gray{Y: v})
+	}
+	out := Match{Reference: reference}.Convert(narrowImage()).(*image.Gray)
+
+	for x, expected := range []uint8{30, 60, 90, 120} {
+		if v := out.GrayAt(x, 0).Y; v != expected {
+			t.Errorf("Invalid matched level, got: %d, want: %d.", v, expected)
+		}
+	}
+}
+
+func TestMatchColor(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 10, G: 10, B: 10, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{R: 20, G: 30, B: 40, A: 0xFF})
+	reference := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	reference.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 50, B: 0, A: 0xFF})
+	reference.SetNRGBA(1, 0, color.NRGBA{R: 200, G: 150, B: 250, A: 0xFF})
+	out := Match{Reference: reference, Color: true}.Convert(input).(*image.RGBA)
+
+	first := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA)
+	if first != (color.NRGBA{R: 100, G: 50, B: 0, A: 0xFF}) {
+		t.Errorf("Invalid matched color, got: %v.", first)
+	}
+	second := color.NRGBAModel.Convert(out.At(1, 0)).(color.NRGBA)
+	if second.R != 200 || second.G != 150 || second.B != 250 {
+		t.Errorf("Invalid matched color, got: %v.", second)
+	}
+}
+
+func TestMatchTarget(t *testing.T) {
+	target := &Histogram{}
+	target[0], target[255] = 1, 1
+	out := Match{Target: target}.Convert(narrowImage()).(*image.Gray)
+
+	if dark, bright := out.GrayAt(1, 0).Y, out.GrayAt(2, 0).Y; dark != 0 || bright != 255 {
+		t.Errorf("Invalid levels matched to target, got: %d and %d, want: %d and %d.", dark, bright, 0, 255)
+	}
+}
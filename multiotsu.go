@@ -0,0 +1,121 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+)
+
+// multiOtsu returns classes-1 levels splitting histogram into classes
+// maximizing between-class variance. Pixels of class i are greater than
+// level i-1 and lower or equal to level i.
+//
+// Maximizing between-class variance is the same as maximizing sum of
+// S*S/P over classes (S - sum of values, P - amount of pixels), which is
+// solved by dynamic programming over bins in O(classes * bins^2)
+func multiOtsu(hist []int, classes int) []int {
+	bins := len(hist)
+	if classes < 2 {
+		return []int{}
+	}
+	if classes > bins {
+		classes = bins
+	}
+
+	// prefix sums of pixel amounts and values
+	p := make([]float64, bins+1)
+	s := make([]float64, bins+1)
+	for t := 0; t < bins; t++ {
+		p[t+1] = p[t] + float64(hist[t])
+		s[t+1] = s[t] + float64(t*hist[t])
+	}
+
+	// cost of class containing bins from i to j-1
+	cost := func(i, j int) float64 {
+		weight := p[j] - p[i]
+		if weight == 0 {
+			return 0
+		}
+		sum := s[j] - s[i]
+		return sum * sum / weight
+	}
+
+	// best[k][j] - best score of first j bins split into k+1 classes
+	// split[k][j] - first bin of the last class of that split
+	best := make([][]float64, classes)
+	split := make([][]int, classes)
+	for k := range best {
+		best[k] = make([]float64, bins+1)
+		split[k] = make([]int, bins+1)
+	}
+	for j := 1; j <= bins; j++ {
+		best[0][j] = cost(0, j)
+	}
+	for k := 1; k < classes; k++ {
+		for j := k + 1; j <= bins; j++ {
+			best[k][j] = -1
+			for i := k; i < j; i++ {
+				if score := best[k-1][i] + cost(i, j); score > best[k][j] {
+					best[k][j] = score
+					split[k][j] = i
+				}
+			}
+		}
+	}
+
+	levels := make([]int, classes-1)
+	j := bins
+	for k := classes - 1; k > 0; k-- {
+		j = split[k][j]
+		levels[k-1] = j - 1
+	}
+	return levels
+}
+
+func (config Threshold) classes() int {
+	if config.Classes < 2 {
+		return 3
+	}
+	return config.Classes
+}
+
+// convertMultiOtsu splits image into classes with multiOtsu levels
+func (config Threshold) convertMultiOtsu(img *image.Gray) (*image.Gray, []uint16) {
+	levels := multiOtsu(histogramGray(img)[:], config.classes())
+
+	expanded := make([]uint16, len(levels))
+	for i, level := range levels {
+		expanded[i] = uint16(level)<<8 | 0xFF
+	}
+
+	traverseImage(img, img, multiLevel{
+		levels:       expanded,
+		labels:       config.Labels,
+		invertColors: config.InvertColors,
+	})
+	return img, expanded
+}
+
+// multiLevel maps pixels to evenly spread gray levels or to class labels.
+// levels are 16-bit, sorted ascending
+type multiLevel struct {
+	levels       []uint16
+	labels       bool
+	invertColors bool
+}
+
+func (config multiLevel) transform(input color.Color) color.Color {
+	r, _, _, _ := input.RGBA()
+
+	class := 0
+	for class < len(config.levels) && uint16(r) > config.levels[class] {
+		class++
+	}
+	if config.invertColors {
+		class = len(config.levels) - class
+	}
+
+	if config.labels {
+		return color.Gray{Y: uint8(class)}
+	}
+	return color.Gray{Y: uint8(class * 255 / len(config.levels))}
+}
@@ -0,0 +1,78 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_multiOtsuTwoClasses(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	hist := make([]int, 256)
+	for i := range hist {
+		hist[i] = random.Intn(100)
+	}
+
+	total := 0
+	for _, amount := range hist {
+		total += amount
+	}
+
+	if levels, expected := multiOtsu(hist, 2), otsu(hist, total); len(levels) != 1 || levels[0] != expected {
+		t.Errorf("Invalid levels, got: %v, want: [%d].", levels, expected)
+	}
+}
+
+func Test_multiOtsu(t *testing.T) {
+	hist := make([]int, 256)
+	for _, peak := range []int{20, 100, 180, 240} {
+		for d := -5; d <= 5; d++ {
+			hist[peak+d] = 50 - 5*abs(d)
+		}
+	}
+
+	levels := multiOtsu(hist, 4)
+	expected := [][2]int{{25, 95}, {105, 175}, {185, 235}}
+
+	if len(levels) != len(expected) {
+		t.Fatalf("Invalid amount of levels, got: %d, want: %d.", len(levels), len(expected))
+	}
+	for i, level := range levels {
+		if level < expected[i][0] || level >= expected[i][1] {
+			t.Errorf("Level %d is not between peaks, got: %d, want: [%d, %d).", i, level, expected[i][0], expected[i][1])
+		}
+	}
+}
+
+func TestThresholdMultiOtsu(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 3, 10))
+	for y := 0; y < 10; y++ {
+		input.Set(0, y, color.Gray{Y: 10})
+		input.Set(1, y, color.Gray{Y: 120})
+		input.Set(2, y, color.Gray{Y: 230})
+	}
+
+	out := Threshold{Algorithm: ThresholdAlgorithms.MultiOtsu, Classes: 3}.Convert(input)
+	labels := Threshold{Algorithm: ThresholdAlgorithms.MultiOtsu, Classes: 3, Labels: true}.Convert(input)
+
+	for x, expected := range []uint8{0, 127, 255} {
+		if y := out.GrayAt(x, 0).Y; y != expected {
+			t.Errorf("Invalid output color, got: %d, want: %d.", y, expected)
+		}
+		if y := labels.GrayAt(x, 0).Y; y != uint8(x) {
+			t.Errorf("Invalid output label, got: %d, want: %d.", y, x)
+		}
+	}
+}
+
+func BenchmarkThresholdMultiOtsu(b *testing.B) {
+	b.StopTimer()
+	input := generateImage()
+	b.StartTimer()
+	for n := 0; n < b.N; n++ {
+		Threshold{
+			Algorithm: ThresholdAlgorithms.MultiOtsu, Classes: 5,
+		}.Convert(input)
+	}
+}
@@ -3,65 +3,228 @@ package pixl
 import (
 	"image"
 	"image/color"
+	"image/draw"
+	"math"
 )
 
+type normalizeModeName string
+
+type normalizeModeList struct {
+	Gray     normalizeModeName
+	Channels normalizeModeName
+	YCbCr    normalizeModeName
+	Lab      normalizeModeName
+}
+
+// NormalizeModes consists of a list of modes that can be used as mode type
+// in pixl.Normalize struct. e.g.
+// pixl.Normalize{Mode: pixl.NormalizeModes.Lab}
+//
+// Gray (default) stretches luminosity grayscale of the image.
+// Channels stretches every RGB channel independently (auto white balance).
+// YCbCr stretches luma and keeps chroma, Lab stretches L* and keeps a*b*,
+// so hue of the image is preserved.
+var NormalizeModes = &normalizeModeList{
+	Gray:     "gray",
+	Channels: "channels",
+	YCbCr:    "ycbcr",
+	Lab:      "lab",
+}
+
 //Normalize is a config struct
+//Configuration contains:
+//  Mode - gray or one of color preserving modes (see pixl.NormalizeModes)
+//  NewMin, NewMax - target range of the output (default 0 and 255)
+//  LowPercentile, HighPercentile - percentiles (0-100) of pixels stretched
+//      to NewMin and NewMax (default 0 and 100), e.g. 0.5 and 99.5 ignore
+//      a few hot pixels. Pixels out of the percentiles are clipped
 type Normalize struct {
+	Mode           normalizeModeName
+	NewMin         uint8
+	NewMax         uint8
+	LowPercentile  float64
+	HighPercentile float64
 }
 
-//Convert takes an image as an input and returns a normalized image
+//Convert takes an image as an input and returns a normalized image.
+//16-bit images are normalized with 16-bit precision and returned as *image.Gray16,
+//other images are returned as *image.Gray. Grayscale of constant image is
+//returned unchanged. Color modes return *image.RGBA64 for 16-bit images
+//and *image.RGBA for other images
 func (config Normalize) Convert(input image.Image) (output image.Image) {
+	switch config.Mode {
+	case NormalizeModes.Channels, NormalizeModes.YCbCr, NormalizeModes.Lab:
+		return config.convertColor(input)
+	}
 
-	output = Gray{
+	gray := Gray{
 		Algorithm: GrayAlgorithms.Luminosity,
-	}.Convert(input)
-
-	oldMax, oldMin := uint8(0), uint8(255)
-
-	max := func(a, b uint8) uint8 {
-		if a > b {
-			return a
-		}
-		return b
 	}
 
-	min := func(a, b uint8) uint8 {
-		if a < b {
-			return a
-		}
-		return b
+	var grayOutput draw.Image
+	if is16Bit(input) {
+		grayOutput = gray.ConvertGray16(input)
+	} else {
+		grayOutput = gray.Convert(input)
 	}
+	output = grayOutput
 
-	for x := 0; x < output.Bounds().Max.X; x++ {
-		for y := 0; y < output.Bounds().Max.Y; y++ {
+	hist := &Histogram16{}
+	bounds := output.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 			r, _, _, _ := output.At(x, y).RGBA()
-			oldMax = max(uint8(r>>8), oldMax)
-			oldMin = min(uint8(r>>8), oldMin)
+			hist[r]++
 		}
 	}
+	low, high := config.percentiles()
+	oldMin, oldMax := hist.Percentile(low), hist.Percentile(high)
 
+	// constant image has nothing to stretch
+	if oldMax <= oldMin {
+		return
+	}
+
+	newMin, newMax := config.targetRange()
 	traverseImage(output, output,
 		normalizeParameters{
-			newMax: 255,
-			newMin: 0,
+			newMax: newMax,
+			newMin: newMin,
 			oldMax: oldMax,
 			oldMin: oldMin,
 		})
 	return
 }
 
+// targetRange returns NewMin and NewMax expanded to 16 bits
+func (config Normalize) targetRange() (newMin, newMax uint16) {
+	min, max := config.NewMin, config.NewMax
+	if max == 0 {
+		max = 0xFF
+	}
+	if min > max {
+		min, max = max, min
+	}
+	return uint16(min) * 0x101, uint16(max) * 0x101
+}
+
+func (config Normalize) percentiles() (low, high float64) {
+	low, high = config.LowPercentile, config.HighPercentile
+	if high == 0 {
+		high = 100
+	}
+	return low, high
+}
+
+// normalizeParameters works on 16-bit values, 8-bit images are expanded to
+// 16 bits by RGBA() (v<<8 | v) and truncated back by the output image
 type normalizeParameters struct {
-	newMax, newMin, oldMax, oldMin uint8
+	newMax, newMin, oldMax, oldMin uint16
 }
 
 func (config normalizeParameters) transform(input color.Color) color.Color {
 	r, _, _, _ := input.RGBA()
-	rr := uint8(r)
-	result := float64(rr-config.oldMin)*
-		(float64(config.newMax-config.newMin)/float64(config.oldMax-config.oldMin)) +
-		float64(config.newMin)
 
-	return color.Gray{
-		Y: uint8(result),
+	// values are stretched as floats, so values out of old range can't
+	// underflow and are clamped to the new range instead
+	scale := (float64(config.newMax) - float64(config.newMin)) /
+		(float64(config.oldMax) - float64(config.oldMin))
+	result := (float64(r)-float64(config.oldMin))*scale + float64(config.newMin)
+	result = math.Max(float64(config.newMin), math.Min(float64(config.newMax), math.Round(result)))
+
+	return color.Gray16{
+		Y: uint16(result),
+	}
+}
+
+// convertColor stretches colors of the image keeping its alpha channel.
+// Fully transparent pixels don't affect ranges of channels
+func (config Normalize) convertColor(input image.Image) image.Image {
+	var output draw.Image
+	if is16Bit(input) {
+		output = image.NewRGBA64(input.Bounds())
+	} else {
+		output = image.NewRGBA(input.Bounds())
+	}
+
+	// histograms of R, G, B channels or of luma or L* (scaled to 16 bits)
+	// in the first one
+	hists := [3]*Histogram16{{}, {}, {}}
+	bounds := input.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			c := color.NRGBA64Model.Convert(input.At(x, y)).(color.NRGBA64)
+			if c.A == 0 {
+				continue
+			}
+			switch config.Mode {
+			case NormalizeModes.YCbCr:
+				hists[0][uint16(math.Round(luma(float64(c.R), float64(c.G), float64(c.B))))]++
+			case NormalizeModes.Lab:
+				l, _, _ := rgbToLab(float64(c.R)/0xFFFF, float64(c.G)/0xFFFF, float64(c.B)/0xFFFF)
+				hists[0][uint16(math.Round(math.Max(0, math.Min(100, l))/100*0xFFFF))]++
+			default:
+				hists[0][c.R]++
+				hists[1][c.G]++
+				hists[2][c.B]++
+			}
+		}
 	}
+
+	low, high := config.percentiles()
+	newMin, newMax := config.targetRange()
+	t := normalizeColor{mode: config.Mode, newMin: float64(newMin), newMax: float64(newMax)}
+	for i, hist := range hists {
+		if hist.Total() == 0 {
+			continue
+		}
+		t.oldMin[i], t.oldMax[i] = float64(hist.Percentile(low)), float64(hist.Percentile(high))
+	}
+
+	traverseImage(input, output, t)
+	return output
+}
+
+// normalizeColor works on 16-bit values, L* is scaled to 16 bits as well
+type normalizeColor struct {
+	mode           normalizeModeName
+	oldMin, oldMax [3]float64
+	newMin, newMax float64
+}
+
+// stretch maps i-th old range onto the new one and clips values out of it,
+// constant ranges are kept
+func (config normalizeColor) stretch(i int, v float64) float64 {
+	if config.oldMax[i] <= config.oldMin[i] {
+		return v
+	}
+	result := (v-config.oldMin[i])*(config.newMax-config.newMin)/(config.oldMax[i]-config.oldMin[i]) + config.newMin
+	return math.Max(config.newMin, math.Min(config.newMax, result))
+}
+
+func (config normalizeColor) transform(input color.Color) color.Color {
+	c := color.NRGBA64Model.Convert(input).(color.NRGBA64)
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+
+	switch config.mode {
+	case NormalizeModes.YCbCr:
+		// Y has weight 1 in every channel of inverse YCbCr transform,
+		// so keeping Cb and Cr shifts all channels by the same delta
+		y := luma(r, g, b)
+		delta := config.stretch(0, y) - y
+		r, g, b = r+delta, g+delta, b+delta
+	case NormalizeModes.Lab:
+		l, a, bb := rgbToLab(r/0xFFFF, g/0xFFFF, b/0xFFFF)
+		l = config.stretch(0, l/100*0xFFFF) / 0xFFFF * 100
+		r, g, b = labToRGB(l, a, bb)
+		r, g, b = r*0xFFFF, g*0xFFFF, b*0xFFFF
+	default:
+		r, g, b = config.stretch(0, r), config.stretch(1, g), config.stretch(2, b)
+	}
+
+	clamp := func(v float64) uint16 {
+		return uint16(math.Max(0, math.Min(0xFFFF, math.Round(v))))
+	}
+
+	return color.NRGBA64{R: clamp(r), G: clamp(g), B: clamp(b), A: c.A}
 }
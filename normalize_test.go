@@ -5,6 +5,7 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	"testing"
+	"testing/quick"
 )
 
 func TestNormalize(t *testing.T) {
@@ -42,6 +43,175 @@ func TestNormalize(t *testing.T) {
 	}
 }
 
+func TestNormalize16Bit(t *testing.T) {
+	input := image.NewGray16(image.Rectangle{Max: image.Point{X: 3, Y: 1}})
+	input.Set(0, 0, color.Gray16{Y: 0x1000})
+	input.Set(1, 0, color.Gray16{Y: 0x1080})
+	input.Set(2, 0, color.Gray16{Y: 0x1100})
+
+	out, ok := Normalize{}.Convert(input).(*image.Gray16)
+	if !ok {
+		t.Fatalf("Invalid output type, got: %T, want: *image.Gray16.", out)
+	}
+
+	for x, expected := range []uint16{0, 0x8000, 0xFFFF} {
+		if y := out.Gray16At(x, 0).Y; y != expected {
+			t.Errorf("Invalid value of pixel, got: %#x, want: %#x.", y, expected)
+		}
+	}
+}
+
+func TestNormalizeConstant(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 2))
+	for i := range input.Pix {
+		input.Pix[i] = 0x42
+	}
+
+	out := Normalize{}.Convert(input).(*image.Gray)
+	for _, v := range out.Pix {
+		if v != 0x42 {
+			t.Fatalf("Constant image should not change, got: %d, want: %d.", v, 0x42)
+		}
+	}
+}
+
+// TestNormalizeSpansRange checks that normalized image always spans the whole
+// target range and keeps order of pixels
+func TestNormalizeSpansRange(t *testing.T) {
+	property8 := func(values []uint8) bool {
+		if len(values) == 0 {
+			return true
+		}
+		input := image.NewGray(image.Rect(0, 0, len(values), 1))
+		copy(input.Pix, values)
+		out := Normalize{}.Convert(input).(*image.Gray)
+		return spansRange(len(values), func(i int) int { return int(values[i]) },
+			func(i int) int { return int(out.Pix[i]) }, 0xFF)
+	}
+
+	property16 := func(values []uint16) bool {
+		if len(values) == 0 {
+			return true
+		}
+		input := image.NewGray16(image.Rect(0, 0, len(values), 1))
+		for x, v := range values {
+			input.SetGray16(x, 0, color.Gray16{Y: v})
+		}
+		out := Normalize{}.Convert(input).(*image.Gray16)
+		return spansRange(len(values), func(i int) int { return int(values[i]) },
+			func(i int) int { return int(out.Gray16At(i, 0).Y) }, 0xFFFF)
+	}
+
+	if err := quick.Check(property8, nil); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(property16, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// spansRange reports whether output spans [0, newMax] (or is unchanged for
+// constant input) and keeps order of input values
+func spansRange(n int, in, out func(int) int, newMax int) bool {
+	minIn, maxIn, minOut, maxOut := in(0), in(0), out(0), out(0)
+	for i := 0; i < n; i++ {
+		minIn, maxIn = minInt(minIn, in(i)), maxInt(maxIn, in(i))
+		minOut, maxOut = minInt(minOut, out(i)), maxInt(maxOut, out(i))
+		for j := 0; j < n; j++ {
+			if in(i) < in(j) && out(i) > out(j) {
+				return false
+			}
+		}
+	}
+	if minIn == maxIn {
+		return minOut == minIn && maxOut == maxIn
+	}
+	return minOut == 0 && maxOut == newMax
+}
+
+func TestNormalizeChannels(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 50, G: 0, B: 20, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{R: 100, G: 255, B: 30, A: 0xFF})
+
+	out := Normalize{Mode: NormalizeModes.Channels}.Convert(input).(*image.RGBA)
+
+	if c, expected := out.RGBAAt(0, 0), (color.RGBA{A: 0xFF}); c != expected {
+		t.Errorf("Invalid value of pixel, got: %v, want: %v.", c, expected)
+	}
+	if c, expected := out.RGBAAt(1, 0), (color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}); c != expected {
+		t.Errorf("Invalid value of pixel, got: %v, want: %v.", c, expected)
+	}
+}
+
+func TestNormalizePreservesHue(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 60, G: 60, B: 60, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{R: 120, G: 90, B: 80, A: 0xFF})
+	input.SetNRGBA(2, 0, color.NRGBA{R: 160, G: 160, B: 160, A: 0xFF})
+
+	for _, mode := range []normalizeModeName{NormalizeModes.YCbCr, NormalizeModes.Lab} {
+		out := Normalize{Mode: mode}.Convert(input).(*image.RGBA)
+
+		if c := out.RGBAAt(0, 0); c.R > 1 || c.G > 1 || c.B > 1 {
+			t.Errorf("Darkest pixel should be black in %s mode, got: %v.", mode, c)
+		}
+		if c := out.RGBAAt(2, 0); c.R < 0xFE || c.G < 0xFE || c.B < 0xFE {
+			t.Errorf("Brightest pixel should be white in %s mode, got: %v.", mode, c)
+		}
+
+		c := out.RGBAAt(1, 0)
+		h, _, _ := rgbToHSV(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+		expected, _, _ := rgbToHSV(120.0/255, 90.0/255, 80.0/255)
+		if h < expected-5 || h > expected+5 {
+			t.Errorf("Hue changed in %s mode, got: %v, want: %v.", mode, h, expected)
+		}
+	}
+}
+
+func TestNormalizeTargetRange(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 3, 1))
+	copy(input.Pix, []uint8{10, 30, 50})
+
+	out := Normalize{NewMin: 20, NewMax: 200}.Convert(input).(*image.Gray)
+
+	for x, expected := range []uint8{20, 110, 200} {
+		if y := out.GrayAt(x, 0).Y; y != expected {
+			t.Errorf("Invalid value of pixel, got: %d, want: %d.", y, expected)
+		}
+	}
+}
+
+func TestNormalizePercentiles(t *testing.T) {
+	// 100 pixels from 50 to 149 and a single hot pixel
+	input := image.NewGray(image.Rect(0, 0, 101, 1))
+	for x := 0; x < 100; x++ {
+		input.Pix[x] = uint8(50 + x)
+	}
+	input.Pix[100] = 255
+
+	out := Normalize{LowPercentile: 1, HighPercentile: 99}.Convert(input).(*image.Gray)
+
+	if y := out.GrayAt(0, 0).Y; y != 0 {
+		t.Errorf("Invalid value of the darkest pixel, got: %d, want: %d.", y, 0)
+	}
+	if y := out.GrayAt(99, 0).Y; y != 255 {
+		t.Errorf("Invalid value of the 99th percentile pixel, got: %d, want: %d.", y, 255)
+	}
+	if y := out.GrayAt(100, 0).Y; y != 255 {
+		t.Errorf("Hot pixel should be clipped, got: %d, want: %d.", y, 255)
+	}
+
+	colorInput := image.NewNRGBA(image.Rect(0, 0, 101, 1))
+	for x := 0; x < 101; x++ {
+		colorInput.SetNRGBA(x, 0, color.NRGBA{R: input.Pix[x], G: input.Pix[x], B: input.Pix[x], A: 0xFF})
+	}
+	colorOut := Normalize{Mode: NormalizeModes.Channels, LowPercentile: 1, HighPercentile: 99}.Convert(colorInput).(*image.RGBA)
+	if c := colorOut.RGBAAt(99, 0); c.R != 255 {
+		t.Errorf("Invalid value of the 99th percentile pixel, got: %d, want: %d.", c.R, 255)
+	}
+}
+
 func BenchmarkNormalize(b *testing.B) {
 	b.StopTimer()
 	input := generateImage()
@@ -50,3 +220,22 @@ func BenchmarkNormalize(b *testing.B) {
 		Normalize{}.Convert(input)
 	}
 }
+
+func TestNormalizeSubImage(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 6, 6))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 120}})
+	for i, v := range []uint8{3, 100, 150, 200} {
+		input.SetGray(2+i%2, 2+i/2, color.Gray{Y: v})
+	}
+	sub := input.SubImage(image.Rect(2, 2, 4, 4))
+
+	gray := Normalize{}.Convert(sub).(*image.Gray)
+	if min, max := gray.GrayAt(2, 2).Y, gray.GrayAt(3, 3).Y; min != 0 || max != 0xFF {
+		t.Errorf("Invalid normalized range of sub image, got: [%d, %d], want: [%d, %d].", min, max, 0, 0xFF)
+	}
+
+	channels := Normalize{Mode: NormalizeModes.Channels}.Convert(sub)
+	if r, _, _, _ := channels.At(2, 2).RGBA(); r != 0 {
+		t.Errorf("Invalid normalized channel of sub image, got: %d, want: %d.", r, 0)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"runtime"
 	"sync"
 )
 
@@ -48,12 +49,11 @@ func parseHexColor(s string) (c color.RGBA, err error) {
 	return
 }
 
-func histogramGray(image *image.Gray) map[int]int {
-	histogram := make(map[int]int)
+func histogramGray(image *image.Gray) *Histogram {
+	histogram := &Histogram{}
 	bounds := image.Bounds()
-	w, h := bounds.Max.X, bounds.Max.Y
-	for x := 0; x < w; x++ {
-		for y := 0; y < h; y++ {
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 			r, _, _, _ := image.At(x, y).RGBA()
 			histogram[int(r>>8)]++
 		}
@@ -61,6 +61,15 @@ func histogramGray(image *image.Gray) map[int]int {
 	return histogram
 }
 
+// is16Bit reports whether an image stores more than 8 bits per channel
+func is16Bit(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray16, *image.RGBA64, *image.NRGBA64:
+		return true
+	}
+	return false
+}
+
 type transformer interface {
 	transform(color.Color) color.Color
 }
@@ -82,3 +91,39 @@ func traverseImage(in image.Image, out image.Image, t transformer) {
 	}
 	waitgroup.Wait()
 }
+
+// parallelBands splits n rows into bands processed by f in parallel,
+// one band per CPU
+func parallelBands(n int, f func(from, to int)) {
+	bands := minInt(runtime.NumCPU(), n)
+	var waitgroup sync.WaitGroup
+	for i := 0; i < bands; i++ {
+		waitgroup.Add(1)
+		go func(from, to int) {
+			f(from, to)
+			waitgroup.Done()
+		}(i*n/bands, (i+1)*n/bands)
+	}
+	waitgroup.Wait()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,267 @@
+package pixl
+
+import (
+	"image"
+	"math"
+)
+
+type resizeFilterName string
+
+type resizeFilterList struct {
+	Nearest    resizeFilterName
+	Box        resizeFilterName
+	Bilinear   resizeFilterName
+	CatmullRom resizeFilterName
+	Mitchell   resizeFilterName
+	Lanczos3   resizeFilterName
+}
+
+// ResizeFilters consists of a list of resampling filters that can be used
+// as filter type in pixl.Resize struct. e.g.
+// pixl.Resize{Filter: pixl.ResizeFilters.Lanczos3}
+//
+// Nearest is the fastest and keeps hard edges (e.g. of pixel art),
+// Box averages covered area, Bilinear is smooth, CatmullRom (default) and
+// Lanczos3 are sharp, Mitchell is a compromise between sharpness and ringing.
+var ResizeFilters = &resizeFilterList{
+	Nearest:    "nearest",
+	Box:        "box",
+	Bilinear:   "bilinear",
+	CatmullRom: "catmull-rom",
+	Mitchell:   "mitchell",
+	Lanczos3:   "lanczos3",
+}
+
+type resizeModeName string
+
+type resizeModeList struct {
+	Exact resizeModeName
+	Fit   resizeModeName
+	Fill  resizeModeName
+}
+
+// ResizeModes consists of a list of modes that can be used as mode type
+// in pixl.Resize struct. e.g.
+// pixl.Resize{Mode: pixl.ResizeModes.Fit}
+//
+// Exact (default) stretches image to Width x Height, Fit scales image to
+// fit inside of Width x Height keeping aspect ratio and Fill scales image
+// to cover Width x Height keeping aspect ratio and crops the center.
+var ResizeModes = &resizeModeList{
+	Exact: "exact",
+	Fit:   "fit",
+	Fill:  "fill",
+}
+
+//Resize is a config struct
+//Configuration contains:
+//  Width, Height - size of the output, 0 is calculated from aspect ratio
+//      of the input
+//  Filter - resampling filter (default Catmull-Rom, see pixl.ResizeFilters)
+//  Mode - exact, fit or fill (default exact, see pixl.ResizeModes)
+//  Linear - if true then image is resampled in linear light, which keeps
+//      brightness of fine details, otherwise sRGB values are resampled
+type Resize struct {
+	Width  int
+	Height int
+	Filter resizeFilterName
+	Mode   resizeModeName
+	Linear bool
+}
+
+//Convert takes an image as an input and returns resized image, *image.RGBA64
+//for 16-bit images and *image.RGBA for other images. Colors are resampled
+//premultiplied by alpha, so transparent pixels don't bleed into edges
+func (config Resize) Convert(input image.Image) image.Image {
+	bounds := input.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	width, height, source := config.size(w, h)
+
+	output := newColorImage(input, image.Rect(0, 0, width, height))
+	if w == 0 || h == 0 || width == 0 || height == 0 {
+		return output
+	}
+
+	pixels := premultiplied(input, config.Linear)
+	filter := config.filter()
+	horizontal := contributions(w, width, source.Min.X, source.Dx(), filter)
+	vertical := contributions(h, height, source.Min.Y, source.Dy(), filter)
+
+	// first pass resamples rows, second pass resamples columns
+	rows := make([]float64, 4*width*h)
+	parallelBands(h, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x, weights := range horizontal {
+				resample(rows[4*(y*width+x):], pixels[4*y*w:], 4, weights)
+			}
+		}
+	})
+	parallelBands(height, func(from, to int) {
+		for y := from; y < to; y++ {
+			c := make([]float64, 4)
+			for x := 0; x < width; x++ {
+				resample(c, rows[4*x:], 4*width, vertical[y])
+				output.Set(x, y, fromPremultiplied(c, config.Linear))
+			}
+		}
+	})
+	return output
+}
+
+// size returns size of the output and area of the input it is resampled
+// from in coordinates relative to the input bounds
+func (config Resize) size(w, h int) (width, height int, source rectangle) {
+	source = rectangle{Max: point{float64(w), float64(h)}}
+	width, height = config.Width, config.Height
+	if w == 0 || h == 0 {
+		return maxInt(width, 0), maxInt(height, 0), source
+	}
+
+	switch {
+	case width <= 0 && height <= 0:
+		return w, h, source
+	case width <= 0:
+		return maxInt(int(math.Round(float64(w*height)/float64(h))), 1), height, source
+	case height <= 0:
+		return width, maxInt(int(math.Round(float64(h*width)/float64(w))), 1), source
+	}
+
+	scaleX, scaleY := float64(width)/float64(w), float64(height)/float64(h)
+	switch config.Mode {
+	case ResizeModes.Fit:
+		scale := math.Min(scaleX, scaleY)
+		width = maxInt(int(math.Round(float64(w)*scale)), 1)
+		height = maxInt(int(math.Round(float64(h)*scale)), 1)
+	case ResizeModes.Fill:
+		scale := math.Max(scaleX, scaleY)
+		dx, dy := (float64(w)-float64(width)/scale)/2, (float64(h)-float64(height)/scale)/2
+		source = rectangle{Min: point{dx, dy}, Max: point{float64(w) - dx, float64(h) - dy}}
+	}
+	return width, height, source
+}
+
+// resizeFilter is a kernel of resampling with radius of its support
+type resizeFilter struct {
+	support float64
+	kernel  func(x float64) float64
+}
+
+func (config Resize) filter() resizeFilter {
+	switch config.Filter {
+	case ResizeFilters.Nearest:
+		return resizeFilter{}
+	case ResizeFilters.Box:
+		return resizeFilter{0.5, func(x float64) float64 {
+			if x >= -0.5 && x < 0.5 {
+				return 1
+			}
+			return 0
+		}}
+	case ResizeFilters.Bilinear:
+		return resizeFilter{1, func(x float64) float64 {
+			return math.Max(0, 1-math.Abs(x))
+		}}
+	case ResizeFilters.Mitchell:
+		return resizeFilter{2, func(x float64) float64 { return cubic(x, 1.0/3, 1.0/3) }}
+	case ResizeFilters.Lanczos3:
+		return resizeFilter{3, func(x float64) float64 {
+			if x > -3 && x < 3 {
+				return sinc(x) * sinc(x/3)
+			}
+			return 0
+		}}
+	}
+	return resizeFilter{2, func(x float64) float64 { return cubic(x, 0, 0.5) }}
+}
+
+// cubic is Mitchell-Netravali family of cubic filters
+func cubic(x, b, c float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+	case x < 2:
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	}
+	return 0
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// weight of a source pixel in an output pixel
+type weight struct {
+	index int
+	value float64
+}
+
+// contributions returns normalized weights of source pixels of every output
+// pixel. Area from start to start+length of size source pixels is resampled
+// into outputSize pixels. Filter is stretched when downsampling, so every
+// source pixel contributes to the output. Pixels out of the source are
+// replaced by the nearest edge pixel
+func contributions(size, outputSize int, start, length float64, filter resizeFilter) [][]weight {
+	scale := length / float64(outputSize)
+	filterScale := math.Max(scale, 1)
+	result := make([][]weight, outputSize)
+
+	for i := range result {
+		center := start + (float64(i)+0.5)*scale
+		if filter.kernel == nil {
+			index := minInt(maxInt(int(center), 0), size-1)
+			result[i] = []weight{{index, 1}}
+			continue
+		}
+
+		support := filter.support * filterScale
+		sum := 0.0
+		for j := int(math.Floor(center - support)); j <= int(math.Ceil(center+support)); j++ {
+			value := filter.kernel((float64(j) + 0.5 - center) / filterScale)
+			if value == 0 {
+				continue
+			}
+			index := minInt(maxInt(j, 0), size-1)
+			if n := len(result[i]); n > 0 && result[i][n-1].index == index {
+				result[i][n-1].value += value
+			} else {
+				result[i] = append(result[i], weight{index, value})
+			}
+			sum += value
+		}
+		for j := range result[i] {
+			result[i][j].value /= sum
+		}
+	}
+	return result
+}
+
+// resample sums 4 channels of pixels with weights into out, stride is
+// a distance between adjacent pixels of in
+func resample(out, in []float64, stride int, weights []weight) {
+	var r, g, b, a float64
+	for _, w := range weights {
+		p := in[w.index*stride:]
+		r += p[0] * w.value
+		g += p[1] * w.value
+		b += p[2] * w.value
+		a += p[3] * w.value
+	}
+	out[0], out[1], out[2], out[3] = r, g, b, a
+}
+
+type point struct {
+	X, Y float64
+}
+
+// rectangle is image.Rectangle with float coordinates
+type rectangle struct {
+	Min, Max point
+}
+
+func (r rectangle) Dx() float64 { return r.Max.X - r.Min.X }
+func (r rectangle) Dy() float64 { return r.Max.Y - r.Min.Y }
@@ -0,0 +1,100 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeSize(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 400, 200))
+	tests := []struct {
+		config        Resize
+		width, height int
+	}{
+		{Resize{Width: 100, Height: 100}, 100, 100},
+		{Resize{Width: 100}, 100, 50},
+		{Resize{Height: 100}, 200, 100},
+		{Resize{Width: 100, Height: 100, Mode: ResizeModes.Fit}, 100, 50},
+		{Resize{Width: 100, Height: 100, Mode: ResizeModes.Fill}, 100, 100},
+		{Resize{}, 400, 200},
+	}
+
+	for _, test := range tests {
+		size := test.config.Convert(input).Bounds().Size()
+		if size.X != test.width || size.Y != test.height {
+			t.Errorf("Invalid size in %s mode, got: %v, want: (%d,%d).", test.config.Mode, size, test.width, test.height)
+		}
+	}
+}
+
+func TestResizeConstant(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 7, 5))
+	traverseImage(input, input, paintAll{color: color.Gray{Y: 100}})
+	filters := []resizeFilterName{ResizeFilters.Nearest, ResizeFilters.Box, ResizeFilters.Bilinear,
+		ResizeFilters.CatmullRom, ResizeFilters.Mitchell, ResizeFilters.Lanczos3}
+
+	for _, filter := range filters {
+		for _, width := range []int{3, 20} {
+			out := Resize{Width: width, Height: 9, Filter: filter}.Convert(input)
+			for x := 0; x < width; x++ {
+				if r, _, _, _ := out.At(x, 4).RGBA(); r>>8 != 100 {
+					t.Errorf("Constant image should stay constant with %s filter, got: %d, want: %d.", filter, r>>8, 100)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestResizeBoxAndGamma(t *testing.T) {
+	// columns alternate between black and white
+	input := image.NewGray(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x += 2 {
+		input.SetGray(x, 0, color.Gray{Y: 0xFF})
+		input.SetGray(x, 1, color.Gray{Y: 0xFF})
+	}
+
+	// average of sRGB values and of linear light which is brighter
+	for _, test := range []struct {
+		linear   bool
+		expected uint8
+	}{{false, 128}, {true, 188}} {
+		out := Resize{Width: 2, Height: 1, Filter: ResizeFilters.Box, Linear: test.linear}.Convert(input).(*image.RGBA)
+		if v := out.RGBAAt(1, 0).R; v != test.expected {
+			t.Errorf("Invalid averaged level with linear %v, got: %d, want: %d.", test.linear, v, test.expected)
+		}
+	}
+}
+
+func TestResizePremultipliedAlpha(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, A: 0xFF})
+	input.SetNRGBA(1, 0, color.NRGBA{G: 0xFF, A: 0x00})
+	out := Resize{Width: 1, Height: 1, Filter: ResizeFilters.Box}.Convert(input)
+
+	// transparent green doesn't bleed into red
+	c := color.NRGBAModel.Convert(out.At(0, 0)).(color.NRGBA)
+	if c.R != 0xFF || c.G != 0 || c.A != 0x80 {
+		t.Errorf("Invalid color of resized edge, got: %v, want: {255 0 0 128}.", c)
+	}
+}
+
+func TestResizeNearest(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 1))
+	input.SetGray(1, 0, color.Gray{Y: 200})
+	out := Resize{Width: 4, Height: 1, Filter: ResizeFilters.Nearest}.Convert(input).(*image.RGBA)
+
+	for x, expected := range []uint8{0, 0, 200, 200} {
+		if v := out.RGBAAt(x, 0).R; v != expected {
+			t.Errorf("Invalid nearest level, got: %d, want: %d.", v, expected)
+		}
+	}
+}
+
+func BenchmarkResize(b *testing.B) {
+	img := generateImage()
+	for i := 0; i < b.N; i++ {
+		Resize{Width: 300, Filter: ResizeFilters.Lanczos3}.Convert(img)
+	}
+}
@@ -0,0 +1,157 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+//Rotate is a config struct
+//Configuration contains:
+//  Angle - clockwise angle in degrees, multiples of 90 are lossless
+//  Interpolation - sampling of other angles (default bilinear,
+//      see pixl.Interpolations)
+//  Expand - if true then canvas is expanded to fit the whole rotated image,
+//      otherwise it keeps size of the input and corners are cropped
+//  Fill - color of uncovered canvas in hex format (e.g. #b690d9),
+//      transparent when empty
+type Rotate struct {
+	Angle         float64
+	Interpolation interpolationName
+	Expand        bool
+	Fill          string
+}
+
+//Convert takes an image as an input and returns rotated image. Multiples of
+//90 degrees keep type of the input, other angles return *image.RGBA64
+//for 16-bit images and *image.RGBA for other images
+func (config Rotate) Convert(input image.Image) image.Image {
+	angle := math.Mod(config.Angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+
+	w, h := input.Bounds().Dx(), input.Bounds().Dy()
+	switch angle {
+	case 0:
+		return remap(input, w, h, func(x, y int) (int, int) { return x, y })
+	case 90:
+		return remap(input, h, w, func(x, y int) (int, int) { return y, h - 1 - x })
+	case 180:
+		return remap(input, w, h, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y })
+	case 270:
+		return remap(input, h, w, func(x, y int) (int, int) { return w - 1 - y, x })
+	}
+
+	fill, err := parseHexColor(config.Fill)
+	if err != nil {
+		fill = color.RGBA{}
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	width, height := w, h
+	if config.Expand {
+		// tiny epsilon keeps exact sizes from growing by rounding errors
+		width = int(math.Ceil(math.Abs(float64(w)*cos) + math.Abs(float64(h)*sin) - 1e-9))
+		height = int(math.Ceil(math.Abs(float64(w)*sin) + math.Abs(float64(h)*cos) - 1e-9))
+	}
+
+	s := newSampler(input, config.Interpolation, BorderModes.Constant, fill)
+	output := newColorImage(input, image.Rect(0, 0, width, height))
+	parallelBands(height, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < width; x++ {
+				// inverse rotation of the center of output pixel around centers
+				// of both images
+				dx, dy := float64(x)+0.5-float64(width)/2, float64(y)+0.5-float64(height)/2
+				sx := dx*cos + dy*sin + float64(w)/2 - 0.5
+				sy := -dx*sin + dy*cos + float64(h)/2 - 0.5
+				output.Set(x, y, fromPremultiplied(s.at(sx, sy), false))
+			}
+		}
+	})
+	return output
+}
+
+//Flip is a config struct
+//Configuration contains:
+//  Horizontal - if true then image is mirrored left to right
+//  Vertical - if true then image is mirrored top to bottom
+type Flip struct {
+	Horizontal bool
+	Vertical   bool
+}
+
+//Convert takes an image as an input and returns flipped image of the same type
+func (config Flip) Convert(input image.Image) image.Image {
+	w, h := input.Bounds().Dx(), input.Bounds().Dy()
+	return remap(input, w, h, func(x, y int) (int, int) {
+		if config.Horizontal {
+			x = w - 1 - x
+		}
+		if config.Vertical {
+			y = h - 1 - y
+		}
+		return x, y
+	})
+}
+
+//Transpose is a config struct
+//Configuration contains:
+//  Transverse - if true then image is mirrored over the anti-diagonal,
+//      otherwise over the main diagonal
+type Transpose struct {
+	Transverse bool
+}
+
+//Convert takes an image as an input and returns transposed image of the same type
+func (config Transpose) Convert(input image.Image) image.Image {
+	w, h := input.Bounds().Dx(), input.Bounds().Dy()
+	return remap(input, h, w, func(x, y int) (int, int) {
+		if config.Transverse {
+			return w - 1 - y, h - 1 - x
+		}
+		return y, x
+	})
+}
+
+// remap returns width x height image of the same type as input, which pixel
+// (x, y) is pixel source(x, y) of the input relative to its bounds
+func remap(input image.Image, width, height int, source func(x, y int) (int, int)) image.Image {
+	min := input.Bounds().Min
+	output := newImageLike(input, image.Rect(0, 0, width, height))
+	parallelBands(height, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < width; x++ {
+				sx, sy := source(x, y)
+				output.Set(x, y, input.At(min.X+sx, min.Y+sy))
+			}
+		}
+	})
+	return output
+}
+
+// newImageLike returns an empty image of the same type as input, images of
+// other types are stored losslessly as *image.RGBA64
+func newImageLike(input image.Image, bounds image.Rectangle) draw.Image {
+	switch img := input.(type) {
+	case *image.Gray:
+		return image.NewGray(bounds)
+	case *image.Gray16:
+		return image.NewGray16(bounds)
+	case *image.Alpha:
+		return image.NewAlpha(bounds)
+	case *image.Alpha16:
+		return image.NewAlpha16(bounds)
+	case *image.RGBA:
+		return image.NewRGBA(bounds)
+	case *image.NRGBA:
+		return image.NewNRGBA(bounds)
+	case *image.NRGBA64:
+		return image.NewNRGBA64(bounds)
+	case *image.Paletted:
+		return image.NewPaletted(bounds, img.Palette)
+	}
+	return image.NewRGBA64(bounds)
+}
@@ -0,0 +1,91 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// numberedImage is w x h image with levels 10*y + x
+func numberedImage(w, h int) *image.Gray {
+	input := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			input.SetGray(x, y, color.Gray{Y: uint8(10*y + x)})
+		}
+	}
+	return input
+}
+
+func TestLosslessTransformations(t *testing.T) {
+	input := numberedImage(3, 2)
+	tests := []struct {
+		name     string
+		output   image.Image
+		expected [][]uint8
+	}{
+		{"rotation by 90", Rotate{Angle: 90}.Convert(input), [][]uint8{{10, 0}, {11, 1}, {12, 2}}},
+		{"rotation by 180", Rotate{Angle: -180}.Convert(input), [][]uint8{{12, 11, 10}, {2, 1, 0}}},
+		{"rotation by 270", Rotate{Angle: 270}.Convert(input), [][]uint8{{2, 12}, {1, 11}, {0, 10}}},
+		{"horizontal flip", Flip{Horizontal: true}.Convert(input), [][]uint8{{2, 1, 0}, {12, 11, 10}}},
+		{"vertical flip", Flip{Vertical: true}.Convert(input), [][]uint8{{10, 11, 12}, {0, 1, 2}}},
+		{"transposition", Transpose{}.Convert(input), [][]uint8{{0, 10}, {1, 11}, {2, 12}}},
+		{"transverse", Transpose{Transverse: true}.Convert(input), [][]uint8{{12, 2}, {11, 1}, {10, 0}}},
+	}
+
+	for _, test := range tests {
+		out, ok := test.output.(*image.Gray)
+		if !ok {
+			t.Errorf("Type of the image should be kept by %s.", test.name)
+			continue
+		}
+		for y, row := range test.expected {
+			for x, expected := range row {
+				if v := out.GrayAt(x, y).Y; v != expected {
+					t.Errorf("Invalid level at (%d, %d) after %s, got: %d, want: %d.", x, y, test.name, v, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestRotateArbitrary(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	traverseImage(input, input, paintAll{color: color.NRGBA{R: 200, G: 100, B: 50, A: 0xFF}})
+
+	cropped := Rotate{Angle: 45, Fill: "#0000ff"}.Convert(input).(*image.RGBA)
+	if size := cropped.Bounds().Size(); size.X != 10 || size.Y != 10 {
+		t.Errorf("Invalid size of cropped canvas, got: %v, want: (10,10).", size)
+	}
+	if c := cropped.RGBAAt(5, 5); c != (color.RGBA{R: 200, G: 100, B: 50, A: 0xFF}) {
+		t.Errorf("Invalid color of the center, got: %v.", c)
+	}
+	if c := cropped.RGBAAt(0, 0); c != (color.RGBA{B: 0xFF, A: 0xFF}) {
+		t.Errorf("Invalid fill color of the corner, got: %v.", c)
+	}
+
+	expanded := Rotate{Angle: 45, Expand: true, Interpolation: Interpolations.Bicubic}.Convert(input)
+	if size := expanded.Bounds().Size(); size.X != 15 || size.Y != 15 {
+		t.Errorf("Invalid size of expanded canvas, got: %v, want: (15,15).", size)
+	}
+	if _, _, _, a := expanded.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("Default fill should be transparent, got alpha: %d.", a)
+	}
+}
+
+func TestRotateDirection(t *testing.T) {
+	// almost quarter turn samples nearly the same pixels as the lossless one
+	input := numberedImage(5, 5)
+	lossless := Rotate{Angle: 90}.Convert(input)
+	sampled := Rotate{Angle: 90.001, Interpolation: Interpolations.Nearest}.Convert(input)
+
+	for y := 1; y < 4; y++ {
+		for x := 1; x < 4; x++ {
+			r1, _, _, _ := lossless.At(x, y).RGBA()
+			r2, _, _, _ := sampled.At(x, y).RGBA()
+			if r1>>8 != r2>>8 {
+				t.Errorf("Invalid level at (%d, %d), got: %d, want: %d.", x, y, r2>>8, r1>>8)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+type interpolationName string
+
+type interpolationList struct {
+	Nearest  interpolationName
+	Bilinear interpolationName
+	Bicubic  interpolationName
+}
+
+// Interpolations consists of a list of interpolations that can be used as
+// interpolation type in geometric transformations. e.g.
+// pixl.Rotate{Interpolation: pixl.Interpolations.Bicubic}
+//
+// Bilinear is the default one, Bicubic (Catmull-Rom) is sharper.
+var Interpolations = &interpolationList{
+	Nearest:  "nearest",
+	Bilinear: "bilinear",
+	Bicubic:  "bicubic",
+}
+
+type borderModeName string
+
+type borderModeList struct {
+	Constant borderModeName
+	Clamp    borderModeName
+	Reflect  borderModeName
+	Wrap     borderModeName
+}
+
+// BorderModes consists of a list of modes of reading pixels out of the
+// image that can be used as border type in geometric transformations. e.g.
+// pixl.Warp{Border: pixl.BorderModes.Reflect}
+//
+// Constant (default) uses fill color, Clamp repeats the edge pixel,
+// Reflect mirrors the image (cba|abc|cba) and Wrap tiles it (abc|abc|abc).
+var BorderModes = &borderModeList{
+	Constant: "constant",
+	Clamp:    "clamp",
+	Reflect:  "reflect",
+	Wrap:     "wrap",
+}
+
+// index maps index i of n pixels long axis into the image, false means
+// fill color, which is always used by empty axis
+func (mode borderModeName) index(i, n int) (int, bool) {
+	if i >= 0 && i < n {
+		return i, true
+	}
+	// empty axis has nothing to repeat
+	if n <= 0 {
+		return 0, false
+	}
+	switch mode {
+	case BorderModes.Clamp:
+		return minInt(maxInt(i, 0), n-1), true
+	case BorderModes.Reflect:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i, true
+	case BorderModes.Wrap:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// sampler reads premultiplied pixels of an image at non-integer positions,
+// pixels out of the image are read according to border mode
+type sampler struct {
+	pixels        []float64
+	w, h          int
+	interpolation interpolationName
+	border        borderModeName
+	fill          [4]float64
+}
+
+func newSampler(input image.Image, interpolation interpolationName, border borderModeName, fill color.Color) sampler {
+	r, g, b, a := fill.RGBA()
+	return sampler{
+		pixels:        premultiplied(input, false),
+		w:             input.Bounds().Dx(),
+		h:             input.Bounds().Dy(),
+		interpolation: interpolation,
+		border:        border,
+		fill:          [4]float64{float64(r) / 0xFFFF, float64(g) / 0xFFFF, float64(b) / 0xFFFF, float64(a) / 0xFFFF},
+	}
+}
+
+// pixel returns channels of pixel (x, y)
+func (s sampler) pixel(x, y int) []float64 {
+	x, insideX := s.border.index(x, s.w)
+	y, insideY := s.border.index(y, s.h)
+	if !insideX || !insideY {
+		return s.fill[:]
+	}
+	i := 4 * (y*s.w + x)
+	return s.pixels[i : i+4]
+}
+
+// at returns channels at position (x, y), where pixel (i, j) is centered at
+// (i, j)
+func (s sampler) at(x, y float64) []float64 {
+	c := make([]float64, 4)
+	switch s.interpolation {
+	case Interpolations.Nearest:
+		copy(c, s.pixel(int(math.Round(x)), int(math.Round(y))))
+	case Interpolations.Bicubic:
+		s.interpolate(c, x, y, 2, func(d float64) float64 { return cubic(d, 0, 0.5) })
+	default:
+		s.interpolate(c, x, y, 1, func(d float64) float64 { return math.Max(0, 1-math.Abs(d)) })
+	}
+	return c
+}
+
+// interpolate sums pixels of 2*radius x 2*radius neighborhood weighted by
+// separable kernel
+func (s sampler) interpolate(c []float64, x, y float64, radius int, kernel func(d float64) float64) {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	for j := y0 - radius + 1; j <= y0+radius; j++ {
+		wy := kernel(y - float64(j))
+		if wy == 0 {
+			continue
+		}
+		for i := x0 - radius + 1; i <= x0+radius; i++ {
+			w := wy * kernel(x-float64(i))
+			if w == 0 {
+				continue
+			}
+			p := s.pixel(i, j)
+			for k := range c {
+				c[k] += p[k] * w
+			}
+		}
+	}
+}
+
+// premultiplied returns premultiplied channels of the image in range 0-1,
+// converted into linear light if required
+func premultiplied(input image.Image, linear bool) []float64 {
+	bounds := input.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	pixels := make([]float64, 4*w*h)
+	parallelBands(h, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < w; x++ {
+				c := color.NRGBA64Model.Convert(input.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+				r, g, b, a := float64(c.R)/0xFFFF, float64(c.G)/0xFFFF, float64(c.B)/0xFFFF, float64(c.A)/0xFFFF
+				if linear {
+					r, g, b = srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+				}
+				i := 4 * (y*w + x)
+				pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = r*a, g*a, b*a, a
+			}
+		}
+	})
+	return pixels
+}
+
+// fromPremultiplied clamps premultiplied channels, which may overshoot
+// with sharp filters, and converts them back from linear light if required
+func fromPremultiplied(c []float64, linear bool) color.RGBA64 {
+	a := math.Max(0, math.Min(1, c[3]))
+	channel := func(v float64) uint16 {
+		v = math.Max(0, math.Min(a, v))
+		if linear && a > 0 {
+			v = linearToSRGB(v/a) * a
+		}
+		return uint16(math.Round(v * 0xFFFF))
+	}
+	return color.RGBA64{R: channel(c[0]), G: channel(c[1]), B: channel(c[2]), A: uint16(math.Round(a * 0xFFFF))}
+}
+
+// newColorImage returns *image.RGBA64 for 16-bit images and *image.RGBA
+// for other images
+func newColorImage(input image.Image, bounds image.Rectangle) draw.Image {
+	if is16Bit(input) {
+		return image.NewRGBA64(bounds)
+	}
+	return image.NewRGBA(bounds)
+}
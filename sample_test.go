@@ -0,0 +1,63 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSampler(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 1))
+	input.SetGray(1, 0, color.Gray{Y: 0xFF})
+	fill := color.RGBA{R: 0xFF, A: 0xFF}
+
+	tests := []struct {
+		interpolation interpolationName
+		x             float64
+		expected      float64
+	}{
+		{Interpolations.Bilinear, 0.25, 0.25},
+		{Interpolations.Nearest, 0.75, 1},
+		{Interpolations.Bicubic, 1, 1},
+	}
+	for _, test := range tests {
+		s := newSampler(input, test.interpolation, BorderModes.Constant, fill)
+		if c := s.at(test.x, 0); c[1] != test.expected {
+			t.Errorf("Invalid %s sample, got: %v, want: %v.", test.interpolation, c[1], test.expected)
+		}
+	}
+
+	if c := newSampler(input, Interpolations.Nearest, BorderModes.Constant, fill).at(-2, 0); c[0] != 1 || c[1] != 0 {
+		t.Errorf("Pixels out of the image should have fill color, got: %v.", c)
+	}
+}
+
+func TestBorderModes(t *testing.T) {
+	tests := []struct {
+		mode     borderModeName
+		expected []int
+	}{
+		{BorderModes.Clamp, []int{0, 0, 0, 1, 2, 2, 2}},
+		{BorderModes.Reflect, []int{1, 0, 0, 1, 2, 2, 1}},
+		{BorderModes.Wrap, []int{1, 2, 0, 1, 2, 0, 1}},
+	}
+
+	for _, test := range tests {
+		for i, expected := range test.expected {
+			if index, inside := test.mode.index(i-2, 3); !inside || index != expected {
+				t.Errorf("Invalid %s index of %d, got: %d, want: %d.", test.mode, i-2, index, expected)
+			}
+		}
+	}
+	if _, inside := BorderModes.Constant.index(-1, 3); inside {
+		t.Errorf("Constant border should use fill color.")
+	}
+}
+
+func TestBorderModesEmpty(t *testing.T) {
+	for _, mode := range []borderModeName{BorderModes.Constant, BorderModes.Clamp, BorderModes.Reflect, BorderModes.Wrap} {
+		if _, inside := mode.index(-1, 0); inside {
+			t.Errorf("Empty axis should use fill color with %s border.", mode)
+		}
+	}
+}
@@ -3,21 +3,94 @@ package pixl
 import (
 	"image"
 	"image/color"
+	"math"
 )
 
 type thresholdAlgoName string
 
+type thresholdModeName string
+
+type thresholdModeList struct {
+	Binary   thresholdModeName
+	Band     thresholdModeName
+	ToZero   thresholdModeName
+	Truncate thresholdModeName
+}
+
+// ThresholdModes consists of a list of modes describing output of global
+// algorithms in pixl.Threshold struct. for ex:
+// pixl.Threshold{Mode: pixl.ThresholdModes.ToZero}
+//
+// Binary (default) turns pixels above level white and others black.
+// Band turns pixels within Levels (inclusive) white and others black,
+// Algorithm is not used. Levels with Min greater than Max select no pixels.
+// ToZero turns pixels lower or equal to level black and keeps others.
+// Truncate turns pixels above level into level and keeps others.
+// InvertColors swaps affected pixels: pixels outside the band are white,
+// pixels above level become black or pixels below level are raised.
+var ThresholdModes = &thresholdModeList{
+	Binary:   "binary",
+	Band:     "band",
+	ToZero:   "to-zero",
+	Truncate: "truncate",
+}
+
 type thresholdAlgoList struct {
-	Static thresholdAlgoName
-	Otsu   thresholdAlgoName
+	Static           thresholdAlgoName
+	Otsu             thresholdAlgoName
+	AdaptiveMean     thresholdAlgoName
+	AdaptiveGaussian thresholdAlgoName
+	Niblack          thresholdAlgoName
+	Sauvola          thresholdAlgoName
+	Wolf             thresholdAlgoName
+	Bernsen          thresholdAlgoName
+	MultiOtsu        thresholdAlgoName
+	Triangle         thresholdAlgoName
+	Kapur            thresholdAlgoName
+	Yen              thresholdAlgoName
+	Li               thresholdAlgoName
+	Huang            thresholdAlgoName
+	IsoData          thresholdAlgoName
+	Mean             thresholdAlgoName
+	Hysteresis       thresholdAlgoName
 }
 
 // ThresholdAlgorithms consists of a list of algorithms that can be used as
 // algorithm type in pixl.Threshold struct. for ex:
 // pixl.Threshold{Algorithm: pixl.ThresholdAlgorithms.Static}
+//
+// AdaptiveMean and AdaptiveGaussian compare every pixel with (weighted) mean
+// of its BlockSize x BlockSize neighbourhood minus C, which copes with uneven
+// lighting of scanned documents.
+// Niblack, Sauvola and Wolf (Wolf-Jolion) compute local level from mean and
+// standard deviation of the neighbourhood, tuned with K and R.
+// Bernsen uses midrange of local minimum and maximum unless local contrast is
+// lower than ContrastLimit.
+// MultiOtsu splits image into Classes classes of gray levels, computed on
+// 8-bit histogram even for 16-bit images.
+// Triangle, Kapur (maximum entropy), Yen, Li (minimum cross-entropy), Huang
+// (fuzzy), IsoData and Mean compute global level from 8-bit histogram, see
+// pixl.Histogram. Triangle copes with unimodal histograms, e.g. sparse text.
+// Hysteresis marks pixels above Levels.Max as white and extends them into
+// connected pixels above Levels.Min, which keeps faint lines in one piece.
 var ThresholdAlgorithms = &thresholdAlgoList{
-	Static: "static",
-	Otsu:   "otsu",
+	Static:           "static",
+	Otsu:             "otsu",
+	AdaptiveMean:     "adaptive-mean",
+	AdaptiveGaussian: "adaptive-gaussian",
+	Niblack:          "niblack",
+	Sauvola:          "sauvola",
+	Wolf:             "wolf",
+	Bernsen:          "bernsen",
+	MultiOtsu:        "multi-otsu",
+	Triangle:         "triangle",
+	Kapur:            "kapur",
+	Yen:              "yen",
+	Li:               "li",
+	Huang:            "huang",
+	IsoData:          "isodata",
+	Mean:             "mean",
+	Hysteresis:       "hysteresis",
 }
 
 //Threshold is a config struct
@@ -25,43 +98,222 @@ var ThresholdAlgorithms = &thresholdAlgoList{
 //  Algorithm - grayscale Algorithm used to convert image
 //  StaticLevel - threshold level is used only with Static Algorithm type
 //  InvertColors - if true then change all white pixel with black pixels
+//  Alpha - policy of handling transparent pixels (see pixl.AlphaPolicies)
+//  Background - color in hex format (e.g. #b690d9) used with Composite policy
+//  BlockSize - odd size of neighbourhood (window) used by local algorithms (default 11)
+//  C - constant subtracted from local mean by adaptive algorithms
+//  K - weight of standard deviation used by Niblack (default -0.2),
+//      Sauvola (default 0.5) and Wolf (default 0.5). Nil means default,
+//      so K 0 turns Niblack into plain local mean
+//  R - dynamic range of standard deviation used by Sauvola (default 128)
+//  ContrastLimit - minimal local contrast used by Bernsen (default 15)
+//  Classes - amount of classes used by MultiOtsu (default 3)
+//  Labels - if true then MultiOtsu returns class numbers (0, 1, 2...)
+//      instead of gray levels evenly spread from black to white
+//  Levels - range of levels 0-255 used by Band mode and Hysteresis
+//      (default {Min: 85, Max: 170}), e.g. {Min: 0, Max: 100} selects
+//      pixels up to 100
+//  Connectivity - 4 or 8 neighbours connected by Hysteresis (default 8)
+//  Mode - output mode of global algorithms (see pixl.ThresholdModes)
+//  ColorFront - color of white pixels in ConvertRGBA output in hex format (default #ffffff)
+//  ColorBackground - color of black pixels in ConvertRGBA output in hex format (default #000000)
 type Threshold struct {
-	Algorithm    thresholdAlgoName
-	StaticLevel  uint8
-	InvertColors bool
+	Algorithm       thresholdAlgoName
+	StaticLevel     uint8
+	InvertColors    bool
+	Alpha           alphaPolicyName
+	Background      string
+	BlockSize       int
+	C               float64
+	K               *float64
+	R               float64
+	ContrastLimit   uint8
+	Classes         int
+	Labels          bool
+	Levels          *Range
+	Connectivity    int
+	Mode            thresholdModeName
+	ColorFront      string
+	ColorBackground string
 }
 
 //Convert takes an image as an input and returns thresholded image
 func (config Threshold) Convert(img image.Image) *image.Gray {
+	out, _ := config.ConvertLevels(img)
+	return out
+}
+
+//ConvertLevels takes an image as an input and returns thresholded image
+//together with 8-bit level(s) used to threshold it. Pixels lower or equal to
+//a level belong to the lower class, Band mode returns inclusive bounds of
+//the band. Local algorithms have no global level, so they return no levels
+func (config Threshold) ConvertLevels(img image.Image) (*image.Gray, []uint8) {
+	out, levels16 := config.ConvertLevels16(img)
+	if levels16 == nil {
+		return out, nil
+	}
+	levels := make([]uint8, len(levels16))
+	for i, level := range levels16 {
+		levels[i] = uint8(level >> 8)
+	}
+	return out, levels
+}
+
+//ConvertLevels16 works as ConvertLevels, but returns 16-bit level(s), so
+//levels of Otsu on 16-bit images keep full precision. 8-bit levels are
+//expanded to cover the whole 8-bit step (level<<8 | 0xFF), Levels of Band
+//mode and Hysteresis are scaled to 16 bits (level*0x101)
+func (config Threshold) ConvertLevels16(img image.Image) (*image.Gray, []uint16) {
 	gray := Gray{
-		Algorithm: GrayAlgorithms.Luminosity,
+		Algorithm:  GrayAlgorithms.Luminosity,
+		Alpha:      config.Alpha,
+		Background: config.Background,
+	}
+
+	switch config.Algorithm {
+	case ThresholdAlgorithms.AdaptiveMean, ThresholdAlgorithms.AdaptiveGaussian,
+		ThresholdAlgorithms.Niblack, ThresholdAlgorithms.Sauvola,
+		ThresholdAlgorithms.Wolf, ThresholdAlgorithms.Bernsen:
+		return config.convertLocal(gray.ConvertGray16(img)), nil
+	case ThresholdAlgorithms.MultiOtsu:
+		return config.convertMultiOtsu(gray.Convert(img))
+	case ThresholdAlgorithms.Hysteresis:
+		return config.convertHysteresis(gray.ConvertGray16(img))
 	}
+
+	if config.Mode == ThresholdModes.Band {
+		in := gray.ConvertGray16(img)
+		out := image.NewGray(in.Bounds())
+		low, high := config.levelRange()
+		band := threshold{
+			level:        low,
+			high:         high,
+			mode:         config.Mode,
+			invertColors: config.InvertColors,
+		}
+		traverseImage(in, out, band)
+		return out, []uint16{band.level, band.high}
+	}
+
+	if is16Bit(img) {
+		in := gray.ConvertGray16(img)
+		out := image.NewGray(in.Bounds())
+		level := config.level16(in)
+		traverseImage(in, out, threshold{level: level, mode: config.Mode, invertColors: config.InvertColors})
+		return out, []uint16{level}
+	}
+
 	out := gray.Convert(img)
+	level := config.level(out)
+	traverseImage(out, out, threshold{level: level, mode: config.Mode, invertColors: config.InvertColors})
+	return out, []uint16{level}
+}
+
+//ConvertRGBA takes an image as an input and returns thresholded image
+//painted with ColorFront (white pixels) and ColorBackground (black pixels).
+//Gray pixels of ToZero and Truncate modes are blended between both colors
+func (config Threshold) ConvertRGBA(img image.Image) *image.RGBA {
+	gray := config.Convert(img)
+	output := image.NewRGBA(gray.Bounds())
+
+	front, err := parseHexColor(config.ColorFront)
+	if err != nil {
+		front = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	}
+	background, err := parseHexColor(config.ColorBackground)
+	if err != nil {
+		background = color.RGBA{A: 0xFF}
+	}
+
+	traverseImage(gray, output, colorize{front: front, background: background})
+	return output
+}
+
+// levelRange returns 16-bit levels used by Band mode and Hysteresis
+func (config Threshold) levelRange() (low, high uint16) {
+	levels := config.Levels
+	if levels == nil {
+		levels = &Range{Min: 85, Max: 170}
+	}
+	scale := func(level float64) uint16 {
+		return uint16(math.Round(math.Max(0, math.Min(255, level)) * 0x101))
+	}
+	return scale(levels.Min), scale(levels.Max)
+}
 
+//ConvertAlpha takes an image as an input and returns thresholded image
+//with alpha channel of the input, unless Alpha policy composites it away
+func (config Threshold) ConvertAlpha(img image.Image) *image.NRGBA {
+	if !config.Alpha.keepsAlpha() {
+		output := config.Convert(img)
+		return withAlpha(output, output)
+	}
+	// levels combined with alpha channel must not be premultiplied
+	config.Alpha = AlphaPolicies.Preserve
+	return withAlpha(config.Convert(img), img)
+}
+
+// level returns threshold level of 8-bit image expanded to 16 bits
+func (config Threshold) level(img *image.Gray) uint16 {
+	level := uint8(127)
 	if config.Algorithm == "static" {
-		level := uint8(127)
 		if config.StaticLevel != 0 {
 			level = config.StaticLevel
 		}
-		traverseImage(out, out, threshold{level: level, invertColors: config.InvertColors})
-		return out
+	} else {
+		level = histogramGray(img).Level(config.Algorithm)
 	}
-	//else if config.Algorithm == "otsu" {
-	traverseImage(out, out, threshold{level: calculateThreshold(out), invertColors: config.InvertColors})
-	return out
+	return uint16(level)<<8 | 0xFF
+}
+
+// level16 returns threshold level of 16-bit image. Only Otsu uses all 16 bits,
+// other algorithms work on 8-bit histogram
+func (config Threshold) level16(img *image.Gray16) uint16 {
+	switch config.Algorithm {
+	case ThresholdAlgorithms.Static:
+		return config.level(nil)
+	case ThresholdAlgorithms.Triangle, ThresholdAlgorithms.Kapur, ThresholdAlgorithms.Yen,
+		ThresholdAlgorithms.Li, ThresholdAlgorithms.Huang, ThresholdAlgorithms.IsoData,
+		ThresholdAlgorithms.Mean:
+		return uint16(NewHistogram16(img).Reduce().Level(config.Algorithm))<<8 | 0xFF
+	}
+	return calculateThreshold16(img)
 }
 
+// threshold compares 16-bit gray values, so the level of 8-bit images has
+// to be expanded to cover the whole 8-bit step (level<<8 | 0xFF).
+// Band mode uses level as inclusive lower bound and high as upper bound
 type threshold struct {
-	level        uint8
+	level        uint16
+	high         uint16
+	mode         thresholdModeName
 	invertColors bool
 }
 
 func (config threshold) transform(input color.Color) color.Color {
 	r, _, _, _ := input.RGBA()
+	v := uint16(r)
+
+	switch config.mode {
+	case ThresholdModes.ToZero:
+		if (v <= config.level) != config.invertColors {
+			v = 0
+		}
+		return color.Gray16{Y: v}
+	case ThresholdModes.Truncate:
+		if (v > config.level) != config.invertColors {
+			v = config.level
+		}
+		return color.Gray16{Y: v}
+	}
 
 	var result uint8
 
-	if uint8(r>>8) <= config.level {
+	if config.mode == ThresholdModes.Band {
+		if v >= config.level && v <= config.high {
+			result = 0xFF
+		}
+	} else if v <= config.level {
 		result = 0x00
 	} else {
 		result = 0xFF
@@ -76,13 +328,37 @@ func (config threshold) transform(input color.Color) color.Color {
 	}
 }
 
+// colorize blends gray pixels between background (black) and front (white)
+type colorize struct {
+	front, background color.RGBA
+}
+
+func (config colorize) transform(input color.Color) color.Color {
+	r, _, _, _ := input.RGBA()
+
+	blend := func(front, background uint8) uint8 {
+		return uint8((uint32(front)*r + uint32(background)*(0xFFFF-r)) / 0xFFFF)
+	}
+
+	return color.RGBA{
+		R: blend(config.front.R, config.background.R),
+		G: blend(config.front.G, config.background.G),
+		B: blend(config.front.B, config.background.B),
+		A: blend(config.front.A, config.background.A),
+	}
+}
+
 func calculateThreshold(img *image.Gray) uint8 {
 	hist := histogramGray(img)
+	return uint8(otsu(hist[:], hist.Total()))
+}
 
-	pixelAmount := img.Bounds().Max.X * img.Bounds().Max.Y
+// otsu returns the level maximizing between-class variance of a histogram
+// with any number of bins
+func otsu(hist []int, pixelAmount int) int {
 	sum := 0
 
-	for t := 0; t < 256; t++ {
+	for t := 0; t < len(hist); t++ {
 		sum += t * hist[t]
 	}
 
@@ -92,7 +368,7 @@ func calculateThreshold(img *image.Gray) uint8 {
 
 	varMax := 0.0
 	threshold := 0
-	for t := 0; t < 256; t++ {
+	for t := 0; t < len(hist); t++ {
 		weightB += hist[t]
 		if weightB == 0 {
 			continue
@@ -113,5 +389,10 @@ func calculateThreshold(img *image.Gray) uint8 {
 			threshold = t
 		}
 	}
-	return uint8(threshold)
+	return threshold
+}
+
+func calculateThreshold16(img *image.Gray16) uint16 {
+	hist := NewHistogram16(img)
+	return uint16(otsu(hist[:], hist.Total()))
 }
@@ -91,6 +91,97 @@ func TestThresholdStaticDefaultLevel(t *testing.T) {
 	}
 }
 
+func TestThreshold16Bit(t *testing.T) {
+	size := 10
+	image := image.NewGray16(image.Rectangle{Max: image.Point{X: size, Y: size}})
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			// both halves have the same high byte, only 16-bit precision separates them
+			if j < 3 {
+				image.Set(i, j, color.Gray16{Y: 0x8010})
+			} else {
+				image.Set(i, j, color.Gray16{Y: 0x80F0})
+			}
+		}
+	}
+
+	out := Threshold{Algorithm: ThresholdAlgorithms.Otsu}.Convert(image)
+	blackPixels := 0
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if r, _, _, _ := out.At(i, j).RGBA(); (r >> 8) == 0xFF {
+				blackPixels++
+			}
+		}
+	}
+
+	if expected := 70; blackPixels != expected {
+		t.Errorf("Invalid number of black pixels in image, got: %d, want: %d.", blackPixels, expected)
+	}
+
+	// 16-bit level shows the split, 8-bit level only its high byte
+	_, levels16 := Threshold{Algorithm: ThresholdAlgorithms.Otsu}.ConvertLevels16(image)
+	if len(levels16) != 1 || levels16[0] != 0x8010 {
+		t.Errorf("Invalid 16-bit levels, got: %#x, want: [0x8010].", levels16)
+	}
+	_, levels := Threshold{Algorithm: ThresholdAlgorithms.Otsu}.ConvertLevels(image)
+	if len(levels) != 1 || levels[0] != 0x80 {
+		t.Errorf("Invalid levels, got: %#x, want: [0x80].", levels)
+	}
+}
+
+func TestThresholdModes(t *testing.T) {
+	values := []uint8{10, 100, 150, 220}
+	input := image.NewGray(image.Rect(0, 0, len(values), 1))
+	for x, v := range values {
+		input.SetGray(x, 0, color.Gray{Y: v})
+	}
+
+	tests := []struct {
+		config   Threshold
+		expected []uint8
+	}{
+		{Threshold{Algorithm: ThresholdAlgorithms.Static, StaticLevel: 120, Mode: ThresholdModes.Binary}, []uint8{0, 0, 255, 255}},
+		{Threshold{Mode: ThresholdModes.Band, Levels: &Range{Min: 100, Max: 150}}, []uint8{0, 255, 255, 0}},
+		{Threshold{Mode: ThresholdModes.Band, Levels: &Range{Min: 100, Max: 150}, InvertColors: true}, []uint8{255, 0, 0, 255}},
+		{Threshold{Mode: ThresholdModes.Band, Levels: &Range{Min: 0, Max: 100}}, []uint8{255, 255, 0, 0}},
+		{Threshold{Mode: ThresholdModes.Band, Levels: &Range{Min: 150, Max: 100}}, []uint8{0, 0, 0, 0}},
+		{Threshold{Mode: ThresholdModes.Band}, []uint8{0, 255, 255, 0}},
+		{Threshold{Algorithm: ThresholdAlgorithms.Static, StaticLevel: 120, Mode: ThresholdModes.ToZero}, []uint8{0, 0, 150, 220}},
+		{Threshold{Algorithm: ThresholdAlgorithms.Static, StaticLevel: 120, Mode: ThresholdModes.ToZero, InvertColors: true}, []uint8{10, 100, 0, 0}},
+		{Threshold{Algorithm: ThresholdAlgorithms.Static, StaticLevel: 120, Mode: ThresholdModes.Truncate}, []uint8{10, 100, 120, 120}},
+		{Threshold{Algorithm: ThresholdAlgorithms.Static, StaticLevel: 120, Mode: ThresholdModes.Truncate, InvertColors: true}, []uint8{120, 120, 150, 220}},
+	}
+
+	for _, test := range tests {
+		out := test.config.Convert(input)
+		for x, expected := range test.expected {
+			if y := out.GrayAt(x, 0).Y; y != expected {
+				t.Errorf("Invalid value of pixel %d in %s mode, got: %d, want: %d.", x, test.config.Mode, y, expected)
+			}
+		}
+	}
+}
+
+func TestThresholdConvertRGBA(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 1))
+	input.SetGray(0, 0, color.Gray{Y: 10})
+	input.SetGray(1, 0, color.Gray{Y: 200})
+
+	out := Threshold{
+		Algorithm:       ThresholdAlgorithms.Static,
+		ColorFront:      "#ff0000",
+		ColorBackground: "#0000ff",
+	}.ConvertRGBA(input)
+
+	if c, expected := out.RGBAAt(0, 0), (color.RGBA{B: 0xFF, A: 0xFF}); c != expected {
+		t.Errorf("Invalid background color, got: %v, want: %v.", c, expected)
+	}
+	if c, expected := out.RGBAAt(1, 0), (color.RGBA{R: 0xFF, A: 0xFF}); c != expected {
+		t.Errorf("Invalid front color, got: %v, want: %v.", c, expected)
+	}
+}
+
 func BenchmarkThresholdStatic(b *testing.B) {
 	b.StopTimer()
 	input := generateImage()
@@ -123,3 +214,20 @@ func BenchmarkThresholdStaticWithInvert(b *testing.B) {
 		}.Convert(input)
 	}
 }
+
+func TestCalculateThresholdSubImage(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 8, 8))
+	expected := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			v := uint8(40 + 50*((x+y)%3))
+			input.SetGray(x+4, y+4, color.Gray{Y: v})
+			expected.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	sub := input.SubImage(image.Rect(4, 4, 8, 8)).(*image.Gray)
+
+	if level, want := calculateThreshold(sub), calculateThreshold(expected); level != want {
+		t.Errorf("Invalid level of sub image, got: %d, want: %d.", level, want)
+	}
+}
@@ -0,0 +1,193 @@
+package pixl
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+type channelName string
+
+type channelList struct {
+	All   channelName
+	Red   channelName
+	Green channelName
+	Blue  channelName
+}
+
+// Channels consists of a list of channels that can be used as channel type
+// in pixl.Levels, pixl.Curves and pixl.Gamma structs. e.g.
+// pixl.Levels{Channel: pixl.Channels.Red}
+//
+// All (default) adjusts R, G and B channels with the same table.
+var Channels = &channelList{
+	All:   "all",
+	Red:   "red",
+	Green: "green",
+	Blue:  "blue",
+}
+
+//Levels is a config struct
+//Configuration contains:
+//  Channel - adjusted channel (default all, see pixl.Channels)
+//  InputBlack, InputWhite - input levels mapped to black and white
+//      (default 0 and 255), levels out of the range are clipped
+//  Gamma - gamma of midtones, greater than 1 brightens (default 1)
+//  OutputBlack, OutputWhite - output range (default 0 and 255)
+type Levels struct {
+	Channel     channelName
+	InputBlack  uint8
+	InputWhite  uint8
+	Gamma       float64
+	OutputBlack uint8
+	OutputWhite uint8
+}
+
+//Convert takes an image as an input and returns *image.RGBA with adjusted levels
+func (config Levels) Convert(input image.Image) *image.RGBA {
+	inBlack, inWhite := float64(config.InputBlack), float64(config.InputWhite)
+	if config.InputWhite == 0 {
+		inWhite = 255
+	}
+	outBlack, outWhite := float64(config.OutputBlack), float64(config.OutputWhite)
+	if config.OutputWhite == 0 {
+		outWhite = 255
+	}
+	gamma := config.Gamma
+	if gamma <= 0 {
+		gamma = 1
+	}
+
+	table := newTable(func(v float64) float64 {
+		if inWhite <= inBlack {
+			if v < inBlack {
+				return outBlack
+			}
+			return outWhite
+		}
+		v = math.Max(0, math.Min(1, (v-inBlack)/(inWhite-inBlack)))
+		return outBlack + math.Pow(v, 1/gamma)*(outWhite-outBlack)
+	})
+	return applyTable(input, config.Channel, table)
+}
+
+//Curves is a config struct
+//Configuration contains:
+//  Channel - adjusted channel (default all, see pixl.Channels)
+//  Points - control points of the curve, X is input and Y output level
+//      (0-255). Curve goes through the points with monotone cubic
+//      interpolation and is flat outside of them. Less than 2 points
+//      keep the image unchanged
+type Curves struct {
+	Channel channelName
+	Points  []image.Point
+}
+
+//Convert takes an image as an input and returns *image.RGBA with levels
+//mapped by the curve
+func (config Curves) Convert(input image.Image) *image.RGBA {
+	return applyTable(input, config.Channel, newTable(monotoneCubic(config.Points)))
+}
+
+//Gamma is a config struct
+//Configuration contains:
+//  Channel - adjusted channel (default all, see pixl.Channels)
+//  Gamma - output is input^(1/Gamma) in range 0-1, greater than 1
+//      brightens (default 1)
+type Gamma struct {
+	Channel channelName
+	Gamma   float64
+}
+
+//Convert takes an image as an input and returns *image.RGBA with gamma
+//correction applied
+func (config Gamma) Convert(input image.Image) *image.RGBA {
+	gamma := config.Gamma
+	if gamma <= 0 {
+		gamma = 1
+	}
+	table := newTable(func(v float64) float64 {
+		return 255 * math.Pow(v/255, 1/gamma)
+	})
+	return applyTable(input, config.Channel, table)
+}
+
+// applyTable maps channel of the image with the table
+func applyTable(input image.Image, channel channelName, table *[256]uint8) *image.RGBA {
+	var tables [3]*[256]uint8
+	switch channel {
+	case Channels.Red:
+		tables[0] = table
+	case Channels.Green:
+		tables[1] = table
+	case Channels.Blue:
+		tables[2] = table
+	default:
+		tables = [3]*[256]uint8{table, table, table}
+	}
+
+	output := image.NewRGBA(input.Bounds())
+	traverseImage(input, output, channelLookup{tables: tables})
+	return output
+}
+
+// monotoneCubic returns Fritsch-Carlson interpolation of points, which
+// doesn't overshoot, so monotone points give a monotone curve
+func monotoneCubic(points []image.Point) func(v float64) float64 {
+	sorted := append([]image.Point{}, points...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].X < sorted[j].X })
+
+	// the last of points with the same X wins
+	var xs, ys []float64
+	for _, p := range sorted {
+		if len(xs) > 0 && xs[len(xs)-1] == float64(p.X) {
+			ys[len(ys)-1] = float64(p.Y)
+			continue
+		}
+		xs, ys = append(xs, float64(p.X)), append(ys, float64(p.Y))
+	}
+
+	n := len(xs)
+	if n < 2 {
+		return func(v float64) float64 { return v }
+	}
+
+	// secants and tangents
+	d := make([]float64, n-1)
+	for i := range d {
+		d[i] = (ys[i+1] - ys[i]) / (xs[i+1] - xs[i])
+	}
+	m := make([]float64, n)
+	m[0], m[n-1] = d[0], d[n-2]
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] > 0 {
+			m[i] = (d[i-1] + d[i]) / 2
+		}
+	}
+	for i := range d {
+		if d[i] == 0 {
+			m[i], m[i+1] = 0, 0
+			continue
+		}
+		a, b := m[i]/d[i], m[i+1]/d[i]
+		if s := a*a + b*b; s > 9 {
+			tau := 3 / math.Sqrt(s)
+			m[i], m[i+1] = tau*a*d[i], tau*b*d[i]
+		}
+	}
+
+	return func(v float64) float64 {
+		if v <= xs[0] {
+			return ys[0]
+		}
+		if v >= xs[n-1] {
+			return ys[n-1]
+		}
+		i := sort.SearchFloat64s(xs, v) - 1
+		h := xs[i+1] - xs[i]
+		t := (v - xs[i]) / h
+		t2, t3 := t*t, t*t*t
+		return (2*t3-3*t2+1)*ys[i] + (t3-2*t2+t)*h*m[i] +
+			(-2*t3+3*t2)*ys[i+1] + (t3-t2)*h*m[i+1]
+	}
+}
@@ -0,0 +1,73 @@
+package pixl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 3, 1))
+	for x, v := range []uint8{20, 120, 230} {
+		input.SetGray(x, 0, color.Gray{Y: v})
+	}
+	out := Levels{InputBlack: 20, InputWhite: 220, OutputBlack: 10, OutputWhite: 210}.Convert(input)
+
+	for x, expected := range []uint8{10, 110, 210} {
+		if v := out.RGBAAt(x, 0).G; v != expected {
+			t.Errorf("Invalid level, got: %d, want: %d.", v, expected)
+		}
+	}
+}
+
+func TestLevelsChannel(t *testing.T) {
+	input := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	input.SetNRGBA(0, 0, color.NRGBA{R: 64, G: 64, B: 64, A: 0xFF})
+	out := Levels{Channel: Channels.Blue, Gamma: 2}.Convert(input)
+
+	// 255 * (64 / 255)^(1/2)
+	if c := out.RGBAAt(0, 0); c.R != 64 || c.G != 64 || c.B != 128 {
+		t.Errorf("Invalid color, got: %v, want: {64 64 128 255}.", c)
+	}
+}
+
+func TestGamma(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 1, 1))
+	input.SetGray(0, 0, color.Gray{Y: 128})
+	out := Gamma{Gamma: 0.5}.Convert(input)
+
+	// 255 * (128 / 255)^2
+	if v := out.RGBAAt(0, 0).R; v != 64 {
+		t.Errorf("Invalid gamma corrected level, got: %d, want: %d.", v, 64)
+	}
+}
+
+func TestMonotoneCubic(t *testing.T) {
+	points := []image.Point{{X: 0, Y: 0}, {X: 64, Y: 100}, {X: 128, Y: 110}, {X: 255, Y: 255}}
+	curve := newTable(monotoneCubic(points))
+
+	for _, p := range points {
+		if v := curve[p.X]; int(v) != p.Y {
+			t.Errorf("Curve should go through control point, got: %d, want: %d.", v, p.Y)
+		}
+	}
+	for v := 1; v < len(curve); v++ {
+		if curve[v] < curve[v-1] {
+			t.Errorf("Curve of monotone points should be monotone, got: %d after %d.", curve[v], curve[v-1])
+		}
+	}
+	if identity := newTable(monotoneCubic(nil)); identity[77] != 77 {
+		t.Errorf("Curve without points should be identity, got: %d, want: %d.", identity[77], 77)
+	}
+}
+
+func TestCurvesFlatOutside(t *testing.T) {
+	input := image.NewGray(image.Rect(0, 0, 2, 1))
+	input.SetGray(0, 0, color.Gray{Y: 10})
+	input.SetGray(1, 0, color.Gray{Y: 250})
+	out := Curves{Points: []image.Point{{X: 200, Y: 180}, {X: 50, Y: 30}}}.Convert(input)
+
+	if dark, bright := out.RGBAAt(0, 0).R, out.RGBAAt(1, 0).R; dark != 30 || bright != 180 {
+		t.Errorf("Invalid levels outside of control points, got: %d and %d, want: %d and %d.", dark, bright, 30, 180)
+	}
+}
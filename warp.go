@@ -0,0 +1,176 @@
+package pixl
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+)
+
+//Warp is a config struct
+//Configuration contains:
+//  Matrix - 3x3 homography mapping coordinates of the input into coordinates
+//      of the output. When the last row is zero the first two rows are
+//      a 2x3 affine matrix. Zero matrix keeps the image unchanged
+//  Width, Height - size of the output (default size of the input)
+//  Interpolation - sampling of the input (default bilinear,
+//      see pixl.Interpolations)
+//  Border - reading of pixels out of the input (default constant,
+//      see pixl.BorderModes)
+//  Fill - color of constant border in hex format (e.g. #b690d9),
+//      transparent when empty
+type Warp struct {
+	Matrix        [3][3]float64
+	Width         int
+	Height        int
+	Interpolation interpolationName
+	Border        borderModeName
+	Fill          string
+}
+
+//Convert takes an image as an input and returns warped image, *image.RGBA64
+//for 16-bit images and *image.RGBA for other images. Image is returned
+//unchanged when the matrix is not invertible
+func (config Warp) Convert(input image.Image) image.Image {
+	width, height := config.Width, config.Height
+	if width <= 0 {
+		width = input.Bounds().Dx()
+	}
+	if height <= 0 {
+		height = input.Bounds().Dy()
+	}
+
+	matrix := config.Matrix
+	if matrix == [3][3]float64{} {
+		matrix = [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	}
+	if matrix[2] == [3]float64{} {
+		matrix[2] = [3]float64{0, 0, 1}
+	}
+	inverse, ok := invert3x3(matrix)
+	if !ok {
+		return cropRect(input, input.Bounds())
+	}
+
+	fill, err := parseHexColor(config.Fill)
+	if err != nil {
+		fill = color.RGBA{}
+	}
+
+	s := newSampler(input, config.Interpolation, config.Border, fill)
+	output := newColorImage(input, image.Rect(0, 0, width, height))
+	parallelBands(height, func(from, to int) {
+		for y := from; y < to; y++ {
+			for x := 0; x < width; x++ {
+				// centers of pixels are mapped back into the input
+				u, v, ok := project(inverse, float64(x)+0.5, float64(y)+0.5)
+				if !ok {
+					output.Set(x, y, fromPremultiplied(s.fill[:], false))
+					continue
+				}
+				output.Set(x, y, fromPremultiplied(s.at(u-0.5, v-0.5), false))
+			}
+		}
+	})
+	return output
+}
+
+//Homography takes four corners {x, y} of a quadrilateral of the input (top
+//left, top right, bottom right and bottom left) and returns matrix of
+//pixl.Warp mapping them onto corners of width x height rectangle. Corners
+//are not rounded, so subpixel positions from corner detection are kept, e.g.
+//  matrix, err := pixl.Homography(corners, 800, 600)
+//  output := pixl.Warp{Matrix: matrix, Width: 800, Height: 600}.Convert(input)
+//Error is returned when three of the corners lie on a line
+func Homography(corners [4][2]float64, width, height int) ([3][3]float64, error) {
+	targets := [4][2]float64{{0, 0}, {float64(width), 0}, {float64(width), float64(height)}, {0, float64(height)}}
+
+	// h33 is fixed to 1, each pair of points gives two equations
+	// of the remaining 8 coefficients
+	var system [8][9]float64
+	for i, corner := range corners {
+		x, y := corner[0], corner[1]
+		u, v := targets[i][0], targets[i][1]
+		system[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		system[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	h, ok := solve(system)
+	if !ok {
+		return [3][3]float64{}, errors.New("Homography: degenerate corners")
+	}
+	return [3][3]float64{{h[0], h[1], h[2]}, {h[3], h[4], h[5]}, {h[6], h[7], 1}}, nil
+}
+
+// solve returns solution of 8 linear equations with augmented matrix system
+// by Gaussian elimination with partial pivoting
+func solve(system [8][9]float64) ([8]float64, bool) {
+	n := len(system)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(system[row][col]) > math.Abs(system[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(system[pivot][col]) < 1e-12 {
+			return [8]float64{}, false
+		}
+		system[col], system[pivot] = system[pivot], system[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := system[row][col] / system[col][col]
+			for k := col; k <= n; k++ {
+				system[row][k] -= factor * system[col][k]
+			}
+		}
+	}
+
+	var result [8]float64
+	for row := n - 1; row >= 0; row-- {
+		sum := system[row][n]
+		for k := row + 1; k < n; k++ {
+			sum -= system[row][k] * result[k]
+		}
+		result[row] = sum / system[row][row]
+	}
+	return result, true
+}
+
+// invert3x3 returns inverse of matrix m, false when it is singular
+func invert3x3(m [3][3]float64) ([3][3]float64, bool) {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if math.Abs(det) < 1e-12 {
+		return [3][3]float64{}, false
+	}
+
+	return [3][3]float64{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}, true
+}
+
+// project maps point (x, y) with homography m, false when the point is
+// mapped to infinity
+func project(m [3][3]float64, x, y float64) (float64, float64, bool) {
+	w := m[2][0]*x + m[2][1]*y + m[2][2]
+	if math.Abs(w) < 1e-12 {
+		return 0, 0, false
+	}
+	return (m[0][0]*x + m[0][1]*y + m[0][2]) / w, (m[1][0]*x + m[1][1]*y + m[1][2]) / w, true
+}
@@ -0,0 +1,82 @@
+package pixl
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestHomography(t *testing.T) {
+	// subpixel corners are mapped exactly
+	corners := [4][2]float64{{12.25, 5.5}, {90.75, 14}, {80, 70.125}, {3.5, 60}}
+	matrix, err := Homography(corners, 40, 30)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	targets := [4][2]float64{{0, 0}, {40, 0}, {40, 30}, {0, 30}}
+	for i, corner := range corners {
+		u, v, _ := project(matrix, corner[0], corner[1])
+		if math.Abs(u-targets[i][0]) > 1e-6 || math.Abs(v-targets[i][1]) > 1e-6 {
+			t.Errorf("Invalid projected corner, got: (%v, %v), want: (%v, %v).", u, v, targets[i][0], targets[i][1])
+		}
+	}
+
+	if _, err := Homography([4][2]float64{{0, 0}, {5, 5}, {10, 10}, {0, 10}}, 10, 10); err == nil {
+		t.Errorf("Corners on a line should return an error.")
+	}
+}
+
+func TestWarpRectangle(t *testing.T) {
+	input := numberedImage(8, 6)
+	corners := [4][2]float64{{2, 1}, {6, 1}, {6, 4}, {2, 4}}
+	matrix, _ := Homography(corners, 4, 3)
+	out := Warp{Matrix: matrix, Width: 4, Height: 3, Interpolation: Interpolations.Nearest}.Convert(input)
+
+	// rectangle of the input is just cropped
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			r, _, _, _ := out.At(x, y).RGBA()
+			if expected := uint32(10*(y+1) + x + 2); r>>8 != expected {
+				t.Errorf("Invalid warped level at (%d, %d), got: %d, want: %d.", x, y, r>>8, expected)
+			}
+		}
+	}
+}
+
+func TestWarpAffineBorder(t *testing.T) {
+	input := numberedImage(4, 1)
+	// translation by 2 pixels to the right
+	affine := [3][3]float64{{1, 0, 2}, {0, 1, 0}}
+
+	tests := []struct {
+		border   borderModeName
+		expected []uint32
+	}{
+		{BorderModes.Clamp, []uint32{0, 0, 0, 1}},
+		{BorderModes.Wrap, []uint32{2, 3, 0, 1}},
+		{BorderModes.Reflect, []uint32{1, 0, 0, 1}},
+	}
+	for _, test := range tests {
+		out := Warp{Matrix: affine, Border: test.border}.Convert(input)
+		for x, expected := range test.expected {
+			if r, _, _, _ := out.At(x, 0).RGBA(); r>>8 != expected {
+				t.Errorf("Invalid level with %s border, got: %d, want: %d.", test.border, r>>8, expected)
+			}
+		}
+	}
+
+	constant := Warp{Matrix: affine, Fill: "#ffffff"}.Convert(input)
+	if r, _, _, _ := constant.At(0, 0).RGBA(); r>>8 != 0xFF {
+		t.Errorf("Invalid fill level, got: %d, want: %d.", r>>8, 0xFF)
+	}
+}
+
+func TestWarpEmpty(t *testing.T) {
+	for _, border := range []borderModeName{BorderModes.Constant, BorderModes.Clamp, BorderModes.Reflect, BorderModes.Wrap} {
+		out := Warp{Width: 3, Height: 3, Border: border, Fill: "#ffffff"}.Convert(image.NewRGBA(image.Rect(0, 0, 0, 0)))
+		if r, _, _, a := out.At(1, 1).RGBA(); r != 0xFFFF || a != 0xFFFF {
+			t.Errorf("Warp of empty image with %s border should be filled, got: %d and %d.", border, r, a)
+		}
+	}
+}